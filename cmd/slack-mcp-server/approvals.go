@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+)
+
+const approvalsUsage = `Usage: slack-mcp-server approvals [--config file] [--dir dir] <command>
+
+Commands:
+  list [--all]          list pending actions, or every action with --all
+  show <id>             print an action as JSON
+  approve <id>          approve a pending action; the server posts it
+  edit <id> <text|->    replace the text of a pending action, "-" reads stdin
+  reject <id> [reason]  reject a pending action, the reason is shown to the agent
+`
+
+// runApprovals implements the approvals subcommand and returns the exit code. It works
+// on the queue directory directly, so the server does not need to be reachable; it
+// picks up approvals made here within a few seconds.
+func runApprovals(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("approvals", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, approvalsUsage) }
+	configPath := fs.String("config", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file")
+	dir := fs.String("dir", "", "Approval queue directory, overrides the configuration")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if *dir == "" {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+			return 1
+		}
+		*dir = cfg.Approval.Dir
+	}
+	if *dir == "" {
+		*dir = approval.DefaultDir()
+	}
+	queue, err := approval.Open(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	if err := runApprovalsCommand(queue, cmd, rest, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runApprovalsCommand(queue *approval.Queue, cmd string, args []string, stdout io.Writer) error {
+	reviewer := "cli:" + currentUser()
+
+	needID := func() (string, error) {
+		if len(args) == 0 {
+			return "", fmt.Errorf("%s needs an action id", cmd)
+		}
+		return args[0], nil
+	}
+
+	switch cmd {
+	case "list":
+		var statuses []approval.Status
+		if len(args) == 0 || args[0] != "--all" {
+			statuses = append(statuses, approval.StatusPending)
+		}
+		actions, err := queue.List(statuses...)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATUS\tCHANNEL\tCALLER\tCREATED\tTEXT")
+		for _, a := range actions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.ID, a.Status, a.Channel, a.Caller,
+				a.CreatedAt.Local().Format(time.DateTime), summarize(a.Text))
+		}
+		return w.Flush()
+	case "show":
+		id, err := needID()
+		if err != nil {
+			return err
+		}
+		a, err := queue.Get(id)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(a)
+	case "approve":
+		id, err := needID()
+		if err != nil {
+			return err
+		}
+		if _, err := queue.Approve(id, reviewer); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s approved, the server will post it shortly\n", id)
+	case "edit":
+		id, err := needID()
+		if err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("edit needs the new text, or - to read it from stdin")
+		}
+		text := strings.Join(args[1:], " ")
+		if text == "-" {
+			b, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			text = strings.TrimRight(string(b), "\n")
+		}
+		if _, err := queue.Edit(id, text, reviewer); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s edited, it is still pending approval\n", id)
+	case "reject":
+		id, err := needID()
+		if err != nil {
+			return err
+		}
+		if _, err := queue.Reject(id, reviewer, strings.Join(args[1:], " ")); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s rejected\n", id)
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", cmd, approvalsUsage)
+	}
+	return nil
+}
+
+// summarize shortens text to a single line for the list output.
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return text
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
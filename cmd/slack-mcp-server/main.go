@@ -2,81 +2,157 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/mattn/go-isatty"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var defaultSseHost = "127.0.0.1"
-var defaultSsePort = 13080
-
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "approvals" {
+		os.Exit(runApprovals(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var transport string
 	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio, sse or http)")
 	flag.StringVar(&transport, "transport", "stdio", "Transport type (stdio, sse or http)")
+	var verifyAudit string
+	flag.StringVar(&verifyAudit, "verify-audit", "", "Verify the hash chain of an audit log file and exit")
+	var configPath string
+	flag.StringVar(&configPath, "config", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file, environment variables override its values")
 	flag.Parse()
 
-	logger, err := newLogger(transport)
+	if verifyAudit != "" {
+		n, err := audit.VerifyFile(verifyAudit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "audit log %s is INVALID after %d valid entries: %v\n", verifyAudit, n, err)
+			os.Exit(1)
+		}
+		fmt.Printf("audit log %s is valid (%d entries)\n", verifyAudit, n)
+		return
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	config.Set(cfg)
+
+	logger, logLevel, err := newLogger(transport, cfg.Log)
 	if err != nil {
 		panic(err)
 	}
 	defer logger.Sync()
 
-	err = validateToolConfig(os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL"))
+	for _, msg := range config.DeprecatedEnv() {
+		logger.Warn(msg, zap.String("context", "console"))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), logger)
 	if err != nil {
-		logger.Fatal("error in SLACK_MCP_ADD_MESSAGE_TOOL",
+		logger.Fatal("Failed to set up OpenTelemetry tracing",
 			zap.String("context", "console"),
 			zap.Error(err),
 		)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if configPath != "" {
+		logger.Info("Loaded config file",
+			zap.String("context", "console"),
+			zap.String("path", configPath),
+		)
+		go config.NewWatcher(configPath, logger, func(_, updated *config.Config) {
+			if err := logLevel.UnmarshalText([]byte(updated.Log.Level)); err != nil {
+				logger.Warn("Invalid log level in reloaded config", zap.Error(err))
+			}
+		}).Run(ctx)
+	}
+
 	p := provider.New(transport, logger)
 	s := server.NewMCPServer(p, logger)
 
+	go s.RunApprovals(ctx)
+
+	watchersDone := make(chan struct{})
 	go func() {
+		defer close(watchersDone)
 		var once sync.Once
 
-		newUsersWatcher(p, &once, logger)()
-		newChannelsWatcher(p, &once, logger)()
+		newUsersWatcher(ctx, p, &once, logger)()
+		newChannelsWatcher(ctx, p, &once, logger)()
 	}()
 
+	var listeners []*server.Listener
+
+	host := cfg.Server.Host
+	port := strconv.Itoa(cfg.Server.Port)
+
+	if cfg.Server.MetricsPort != 0 {
+		metricsPort := strconv.Itoa(cfg.Server.MetricsPort)
+
+		metricsServer := s.ServeMetrics()
+		listeners = append(listeners, metricsServer)
+		logger.Info(
+			fmt.Sprintf("Metrics server listening on %s", fmt.Sprintf("%s:%s/metrics", host, metricsPort)),
+			zap.String("context", "console"),
+			zap.String("host", host),
+			zap.String("port", metricsPort),
+		)
+
+		go func() {
+			if err := metricsServer.Start(host + ":" + metricsPort); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Metrics server error",
+					zap.String("context", "console"),
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	stopStdio := func() {}
+
 	switch transport {
 	case "stdio":
 		for {
-			if ready, _ := p.IsReady(); ready {
+			if ready, _ := p.IsReady(); ready || ctx.Err() != nil {
 				break
 			}
 			time.Sleep(100 * time.Millisecond)
 		}
-		if err := s.ServeStdio(); err != nil {
-			logger.Fatal("Server error",
-				zap.String("context", "console"),
-				zap.Error(err),
-			)
-		}
-	case "sse":
-		host := os.Getenv("SLACK_MCP_HOST")
-		if host == "" {
-			host = defaultSseHost
-		}
-		port := os.Getenv("SLACK_MCP_PORT")
-		if port == "" {
-			port = strconv.Itoa(defaultSsePort)
-		}
 
+		// The stdio server gets its own context so that in-flight tool calls keep
+		// running while the server drains after a signal.
+		stdioCtx, cancel := context.WithCancel(context.Background())
+		stopStdio = cancel
+		go func() {
+			serveErr <- s.ServeStdio(stdioCtx)
+		}()
+	case "sse":
 		sseServer := s.ServeSSE(":" + port)
+		listeners = append(listeners, sseServer)
 		logger.Info(
-			fmt.Sprintf("SSE server listening on %s", fmt.Sprintf("%s:%s/sse", host, port)),
+			fmt.Sprintf("SSE server listening on %s", fmt.Sprintf("%s://%s:%s/sse", sseServer.Scheme(), host, port)),
 			zap.String("context", "console"),
 			zap.String("host", host),
 			zap.String("port", port),
@@ -88,25 +164,14 @@ func main() {
 			)
 		}
 
-		if err := sseServer.Start(host + ":" + port); err != nil {
-			logger.Fatal("Server error",
-				zap.String("context", "console"),
-				zap.Error(err),
-			)
-		}
+		go func() {
+			serveErr <- sseServer.Start(host + ":" + port)
+		}()
 	case "http":
-		host := os.Getenv("SLACK_MCP_HOST")
-		if host == "" {
-			host = defaultSseHost
-		}
-		port := os.Getenv("SLACK_MCP_PORT")
-		if port == "" {
-			port = strconv.Itoa(defaultSsePort)
-		}
-
 		httpServer := s.ServeHTTP(":" + port)
+		listeners = append(listeners, httpServer)
 		logger.Info(
-			fmt.Sprintf("HTTP server listening on %s", fmt.Sprintf("%s:%s", host, port)),
+			fmt.Sprintf("HTTP server listening on %s", fmt.Sprintf("%s://%s:%s", httpServer.Scheme(), host, port)),
 			zap.String("context", "console"),
 			zap.String("host", host),
 			zap.String("port", port),
@@ -118,12 +183,9 @@ func main() {
 			)
 		}
 
-		if err := httpServer.Start(host + ":" + port); err != nil {
-			logger.Fatal("Server error",
-				zap.String("context", "console"),
-				zap.Error(err),
-			)
-		}
+		go func() {
+			serveErr <- httpServer.Start(host + ":" + port)
+		}()
 	default:
 		logger.Fatal("Invalid transport type",
 			zap.String("context", "console"),
@@ -131,22 +193,100 @@ func main() {
 			zap.String("allowed", "stdio, sse, http"),
 		)
 	}
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received",
+			zap.String("context", "console"),
+		)
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Server error",
+				zap.String("context", "console"),
+				zap.Error(err),
+			)
+			exitCode = 1
+		}
+	}
+	// Restore default signal handling: a second signal terminates immediately.
+	stop()
+
+	shutdown(s, listeners, stopStdio, watchersDone, shutdownTracing, cfg.Server.ShutdownTimeout, logger)
+
+	if exitCode != 0 {
+		_ = logger.Sync()
+		os.Exit(exitCode)
+	}
 }
 
-func newUsersWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
+// shutdown drains in-flight tool calls, stops the listeners and cache watchers and
+// flushes persistent state, bounded by timeout.
+func shutdown(
+	s *server.MCPServer,
+	listeners []*server.Listener,
+	stopStdio context.CancelFunc,
+	watchersDone <-chan struct{},
+	shutdownTracing func(context.Context) error,
+	timeout time.Duration,
+	logger *zap.Logger,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Tool calls are drained first, while sessions are still open to receive results.
+	_ = s.Shutdown(ctx)
+	stopStdio()
+
+	for _, l := range listeners {
+		if err := l.Shutdown(ctx); err != nil {
+			logger.Warn("Listener shutdown error",
+				zap.String("context", "console"),
+				zap.Error(err),
+			)
+		}
+	}
+
+	select {
+	case <-watchersDone:
+	case <-ctx.Done():
+		logger.Warn("Cache watchers did not stop before the shutdown deadline",
+			zap.String("context", "console"),
+		)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("Failed to flush traces",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+
+	logger.Info("Shutdown complete",
+		zap.String("context", "console"),
+	)
+}
+
+func newUsersWatcher(ctx context.Context, p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
 	return func() {
 		logger.Info("Caching users collection...",
 			zap.String("context", "console"),
 		)
 
-		if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+		if config.Current().Slack.IsDemo() {
 			logger.Info("Demo credentials are set, skip",
 				zap.String("context", "console"),
 			)
 			return
 		}
 
-		err := p.RefreshUsers(context.Background())
+		err := p.RefreshUsers(ctx)
+		if err != nil && ctx.Err() != nil {
+			logger.Info("Stopped caching users, shutting down",
+				zap.String("context", "console"),
+			)
+			return
+		}
 		if err != nil {
 			logger.Fatal("Error booting provider",
 				zap.String("context", "console"),
@@ -165,20 +305,26 @@ func newUsersWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logge
 	}
 }
 
-func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
+func newChannelsWatcher(ctx context.Context, p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
 	return func() {
 		logger.Info("Caching channels collection...",
 			zap.String("context", "console"),
 		)
 
-		if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+		if config.Current().Slack.IsDemo() {
 			logger.Info("Demo credentials are set, skip.",
 				zap.String("context", "console"),
 			)
 			return
 		}
 
-		err := p.RefreshChannels(context.Background())
+		err := p.RefreshChannels(ctx)
+		if err != nil && ctx.Err() != nil {
+			logger.Info("Stopped caching channels, shutting down",
+				zap.String("context", "console"),
+			)
+			return
+		}
 		if err != nil {
 			logger.Fatal("Error booting provider",
 				zap.String("context", "console"),
@@ -197,54 +343,27 @@ func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Lo
 	}
 }
 
-func validateToolConfig(config string) error {
-	if config == "" || config == "true" || config == "1" {
-		return nil
-	}
-
-	items := strings.Split(config, ",")
-	hasNegated := false
-	hasPositive := false
-
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-		if strings.HasPrefix(item, "!") {
-			hasNegated = true
-		} else {
-			hasPositive = true
-		}
-	}
-
-	if hasNegated && hasPositive {
-		return fmt.Errorf("cannot mix allowed and disallowed (! prefixed) channels")
-	}
-
-	return nil
-}
-
-func newLogger(transport string) (*zap.Logger, error) {
+// newLogger builds the logger and returns its level, which follows config reloads.
+func newLogger(transport string, logConfig config.Log) (*zap.Logger, zap.AtomicLevel, error) {
 	atomicLevel := zap.NewAtomicLevelAt(zap.InfoLevel)
-	if envLevel := os.Getenv("SLACK_MCP_LOG_LEVEL"); envLevel != "" {
-		if err := atomicLevel.UnmarshalText([]byte(envLevel)); err != nil {
-			fmt.Printf("Invalid log level '%s': %v, using 'info'\n", envLevel, err)
+	if logConfig.Level != "" {
+		if err := atomicLevel.UnmarshalText([]byte(logConfig.Level)); err != nil {
+			fmt.Printf("Invalid log level '%s': %v, using 'info'\n", logConfig.Level, err)
 		}
 	}
 
-	useJSON := shouldUseJSONFormat()
-	useColors := shouldUseColors() && !useJSON
+	useJSON := shouldUseJSONFormat(logConfig.Format)
+	useColors := shouldUseColors(logConfig.Color) && !useJSON
 
 	outputPath := "stdout"
 	if transport == "stdio" {
 		outputPath = "stderr"
 	}
 
-	var config zap.Config
+	var zapConfig zap.Config
 
 	if useJSON {
-		config = zap.Config{
+		zapConfig = zap.Config{
 			Level:            atomicLevel,
 			Development:      false,
 			Encoding:         "json",
@@ -262,7 +381,7 @@ func newLogger(transport string) (*zap.Logger, error) {
 			},
 		}
 	} else {
-		config = zap.Config{
+		zapConfig = zap.Config{
 			Level:            atomicLevel,
 			Development:      true,
 			Encoding:         "console",
@@ -282,19 +401,19 @@ func newLogger(transport string) (*zap.Logger, error) {
 		}
 	}
 
-	logger, err := config.Build(zap.AddCaller())
+	logger, err := zapConfig.Build(zap.AddCaller())
 	if err != nil {
-		return nil, err
+		return nil, atomicLevel, err
 	}
 
 	logger = logger.With(zap.String("app", "slack-mcp-server"))
 
-	return logger, err
+	return logger, atomicLevel, err
 }
 
 // shouldUseJSONFormat determines if JSON format should be used
-func shouldUseJSONFormat() bool {
-	if format := os.Getenv("SLACK_MCP_LOG_FORMAT"); format != "" {
+func shouldUseJSONFormat(format string) bool {
+	if format != "" {
 		return strings.ToLower(format) == "json"
 	}
 
@@ -320,9 +439,9 @@ func shouldUseJSONFormat() bool {
 	return false
 }
 
-func shouldUseColors() bool {
-	if colorEnv := os.Getenv("SLACK_MCP_LOG_COLOR"); colorEnv != "" {
-		return colorEnv == "true" || colorEnv == "1"
+func shouldUseColors(color string) bool {
+	if color != "" {
+		return color == "true" || color == "1"
 	}
 
 	if os.Getenv("NO_COLOR") != "" {
@@ -0,0 +1,284 @@
+// Package approval holds write actions for human review before they run.
+//
+// Every action is stored as its own JSON file in the queue directory, so the server and
+// the approvals CLI can work on the same queue. Reviewers move an action from pending
+// to approved or rejected, optionally editing its text first; the server's Dispatcher
+// runs approved actions and records whether they were posted or failed.
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is the lifecycle state of an action.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusPosted   Status = "posted"
+	StatusFailed   Status = "failed"
+)
+
+var (
+	// ErrNotFound is returned for unknown action IDs.
+	ErrNotFound = errors.New("action not found")
+	// ErrInvalidState is returned when an action cannot make the requested transition,
+	// for example approving an action that was already rejected.
+	ErrInvalidState = errors.New("invalid action state")
+)
+
+var idPattern = regexp.MustCompile(`^pa_[0-9a-f]{16}$`)
+
+// Action is a write tool call waiting for, or done with, human review.
+type Action struct {
+	ID      string `json:"id"`
+	Tool    string `json:"tool"`
+	Status  Status `json:"status"`
+	Channel string `json:"channel"`
+	// Text is the content to send. Reviewers may edit it while the action is pending.
+	Text string `json:"text"`
+	// Params holds the remaining tool arguments needed to run the action.
+	Params map[string]string `json:"params,omitempty"`
+	// Preview is the rendered Slack Block Kit payload shown to reviewers, if any.
+	Preview   json.RawMessage `json:"preview,omitempty"`
+	Caller    string          `json:"caller"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Reviewer  string          `json:"reviewer,omitempty"`
+	Edited    bool            `json:"edited,omitempty"`
+	Reason    string          `json:"reason,omitempty"`
+	TS        string          `json:"ts,omitempty"`
+	Permalink string          `json:"permalink,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Queue is a directory of actions.
+type Queue struct {
+	dir    string
+	mu     sync.Mutex
+	notify chan struct{}
+}
+
+// DefaultDir returns the queue directory used when none is configured.
+func DefaultDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "approvals"
+	}
+	return filepath.Join(cacheDir, "slack-mcp-server", "approvals")
+}
+
+// Open opens the queue in dir, creating the directory if needed.
+func Open(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create approval queue directory: %w", err)
+	}
+	return &Queue{dir: dir, notify: make(chan struct{}, 1)}, nil
+}
+
+// Dir returns the queue directory.
+func (q *Queue) Dir() string {
+	return q.dir
+}
+
+// Approved is signalled when an action is approved through this Queue, so an in-process
+// Dispatcher can run it without waiting for its next poll.
+func (q *Queue) Approved() <-chan struct{} {
+	return q.notify
+}
+
+// Enqueue stores a as a new pending action. ID, Status and timestamps are set by Enqueue.
+func (q *Queue) Enqueue(a *Action) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	a.ID = id
+	a.Status = StatusPending
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.write(a)
+}
+
+// Get returns the action with the given ID.
+func (q *Queue) Get(id string) (*Action, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.read(id)
+}
+
+// List returns all actions, oldest first, optionally limited to the given statuses.
+func (q *Queue) List(statuses ...Status) ([]*Action, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approval queue: %w", err)
+	}
+	var actions []*Action
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !idPattern.MatchString(id) {
+			continue
+		}
+		a, err := q.read(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(statuses) == 0 || containsStatus(statuses, a.Status) {
+			actions = append(actions, a)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].CreatedAt.Before(actions[j].CreatedAt) })
+	return actions, nil
+}
+
+// Approve marks a pending action as approved.
+func (q *Queue) Approve(id, reviewer string) (*Action, error) {
+	a, err := q.update(id, StatusPending, func(a *Action) {
+		a.Status = StatusApproved
+		a.Reviewer = reviewer
+	})
+	if err == nil {
+		select {
+		case q.notify <- struct{}{}:
+		default:
+		}
+	}
+	return a, err
+}
+
+// Edit replaces the text of a pending action. It stays pending until approved.
+func (q *Queue) Edit(id, text, reviewer string) (*Action, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("text must not be empty")
+	}
+	return q.update(id, StatusPending, func(a *Action) {
+		a.Text = text
+		a.Preview = nil
+		a.Edited = true
+		a.Reviewer = reviewer
+	})
+}
+
+// Reject marks a pending action as rejected, with an optional reason for the agent.
+func (q *Queue) Reject(id, reviewer, reason string) (*Action, error) {
+	return q.update(id, StatusPending, func(a *Action) {
+		a.Status = StatusRejected
+		a.Reviewer = reviewer
+		a.Reason = reason
+	})
+}
+
+// Complete records the outcome of running an approved action.
+func (q *Queue) Complete(id, ts, permalink string, runErr error) (*Action, error) {
+	return q.update(id, StatusApproved, func(a *Action) {
+		if runErr != nil {
+			a.Status = StatusFailed
+			a.Error = runErr.Error()
+			return
+		}
+		a.Status = StatusPosted
+		a.TS = ts
+		a.Permalink = permalink
+	})
+}
+
+// update applies fn to the action if it is in state from.
+func (q *Queue) update(id string, from Status, fn func(*Action)) (*Action, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	a, err := q.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != from {
+		return nil, fmt.Errorf("%w: action %s is %s, not %s", ErrInvalidState, id, a.Status, from)
+	}
+	fn(a)
+	a.UpdatedAt = time.Now().UTC()
+	if err := q.write(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (q *Queue) read(id string) (*Action, error) {
+	if !idPattern.MatchString(id) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	data, err := os.ReadFile(filepath.Join(q.dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action %s: %w", id, err)
+	}
+	var a Action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode action %s: %w", id, err)
+	}
+	return &a, nil
+}
+
+// write replaces the action file atomically, so readers in other processes never see a
+// partial file.
+func (q *Queue) write(a *Action) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode action %s: %w", a.ID, err)
+	}
+	tmp, err := os.CreateTemp(q.dir, ".tmp-"+a.ID+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write action %s: %w", a.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write action %s: %w", a.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write action %s: %w", a.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(q.dir, a.ID+".json")); err != nil {
+		return fmt.Errorf("failed to write action %s: %w", a.ID, err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate action ID: %w", err)
+	}
+	return "pa_" + hex.EncodeToString(b), nil
+}
+
+func containsStatus(statuses []Status, s Status) bool {
+	for _, candidate := range statuses {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	q, err := Open(t.TempDir())
+	require.NoError(t, err)
+	return q
+}
+
+func TestUnitQueueLifecycle(t *testing.T) {
+	q := newTestQueue(t)
+
+	a := &Action{Tool: "conversations_add_message", Channel: "C1", Text: "hello", Caller: "stdio"}
+	require.NoError(t, q.Enqueue(a))
+	assert.Regexp(t, `^pa_[0-9a-f]{16}$`, a.ID)
+	assert.Equal(t, StatusPending, a.Status)
+
+	edited, err := q.Edit(a.ID, "hello, world", "cli:alice")
+	require.NoError(t, err)
+	assert.True(t, edited.Edited)
+	assert.Equal(t, StatusPending, edited.Status)
+
+	_, err = q.Approve(a.ID, "cli:alice")
+	require.NoError(t, err)
+	select {
+	case <-q.Approved():
+	default:
+		t.Fatal("approval was not signalled")
+	}
+
+	_, err = q.Reject(a.ID, "cli:bob", "too late")
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	done, err := q.Complete(a.ID, "1700000000.000100", "https://acme.slack.com/archives/C1/p1700000000000100", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPosted, done.Status)
+
+	// A second queue on the same directory, like the CLI, sees the same state.
+	other, err := Open(q.Dir())
+	require.NoError(t, err)
+	got, err := other.Get(a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", got.Text)
+	assert.Equal(t, "1700000000.000100", got.TS)
+}
+
+func TestUnitQueueListAndErrors(t *testing.T) {
+	q := newTestQueue(t)
+
+	first := &Action{Tool: "conversations_add_message", Channel: "C1", Text: "one"}
+	second := &Action{Tool: "conversations_add_message", Channel: "C2", Text: "two"}
+	require.NoError(t, q.Enqueue(first))
+	require.NoError(t, q.Enqueue(second))
+	_, err := q.Reject(second.ID, "web:127.0.0.1", "off topic")
+	require.NoError(t, err)
+
+	all, err := q.List()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, first.ID, all[0].ID)
+
+	pending, err := q.List(StatusPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, first.ID, pending[0].ID)
+
+	_, err = q.Get("pa_0000000000000000")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = q.Get("../../etc/passwd")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = q.Edit(first.ID, "  ", "cli:alice")
+	assert.Error(t, err)
+}
+
+func TestUnitDispatcher(t *testing.T) {
+	q := newTestQueue(t)
+
+	ok := &Action{Tool: "conversations_add_message", Channel: "C1", Text: "ok"}
+	failing := &Action{Tool: "conversations_add_message", Channel: "C2", Text: "fails"}
+	unknown := &Action{Tool: "unknown_tool", Channel: "C3", Text: "?"}
+	waiting := &Action{Tool: "conversations_add_message", Channel: "C4", Text: "still pending"}
+	for _, a := range []*Action{ok, failing, unknown, waiting} {
+		require.NoError(t, q.Enqueue(a))
+	}
+	for _, a := range []*Action{ok, failing, unknown} {
+		_, err := q.Approve(a.ID, "cli:alice")
+		require.NoError(t, err)
+	}
+
+	var ran []string
+	d := NewDispatcher(q, zap.NewNop())
+	d.Handle("conversations_add_message", func(ctx context.Context, a *Action) (string, string, error) {
+		ran = append(ran, a.ID)
+		if a.Channel == "C2" {
+			return "", "", errors.New("channel_not_found")
+		}
+		return "1.2", "https://acme.slack.com/archives/C1/p12", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d.dispatch(ctx)
+
+	assert.ElementsMatch(t, []string{ok.ID, failing.ID}, ran)
+	for id, want := range map[string]Status{ok.ID: StatusPosted, failing.ID: StatusFailed, unknown.ID: StatusFailed, waiting.ID: StatusPending} {
+		a, err := q.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, a.Status, id)
+	}
+	got, _ := q.Get(failing.ID)
+	assert.Equal(t, "channel_not_found", got.Error)
+
+	// Posted actions are never run twice.
+	d.dispatch(ctx)
+	assert.Len(t, ran, 2)
+}
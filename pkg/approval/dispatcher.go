@@ -0,0 +1,93 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// pollInterval bounds how long an action approved from another process, such as the
+// CLI, waits before it runs.
+const pollInterval = 2 * time.Second
+
+// Executor runs an approved action and returns the timestamp and permalink of the
+// resulting Slack message.
+type Executor func(ctx context.Context, a *Action) (ts, permalink string, err error)
+
+// Dispatcher runs approved actions with the executor registered for their tool.
+type Dispatcher struct {
+	queue     *Queue
+	executors map[string]Executor
+	logger    *zap.Logger
+}
+
+// NewDispatcher returns a dispatcher for q without executors.
+func NewDispatcher(q *Queue, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{queue: q, executors: make(map[string]Executor), logger: logger}
+}
+
+// Handle registers the executor for tool.
+func (d *Dispatcher) Handle(tool string, exec Executor) {
+	d.executors[tool] = exec
+}
+
+// Run dispatches approved actions until ctx is cancelled. An action that has started
+// runs to completion even if ctx is cancelled meanwhile, so it is never left
+// half-posted.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.queue.Approved():
+		}
+	}
+}
+
+// dispatch runs every approved action once.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	actions, err := d.queue.List(StatusApproved)
+	if err != nil {
+		d.logger.Error("Failed to list approved actions", zap.Error(err))
+		return
+	}
+	for _, a := range actions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var ts, permalink string
+		exec, ok := d.executors[a.Tool]
+		if ok {
+			ts, permalink, err = exec(context.WithoutCancel(ctx), a)
+		} else {
+			err = fmt.Errorf("no executor for tool %q", a.Tool)
+		}
+
+		if _, cErr := d.queue.Complete(a.ID, ts, permalink, err); cErr != nil {
+			d.logger.Error("Failed to record action outcome", zap.String("id", a.ID), zap.Error(cErr))
+			continue
+		}
+		if err != nil {
+			d.logger.Warn("Approved action failed",
+				zap.String("id", a.ID),
+				zap.String("tool", a.Tool),
+				zap.Error(err),
+			)
+			continue
+		}
+		d.logger.Info("Approved action posted",
+			zap.String("id", a.ID),
+			zap.String("tool", a.Tool),
+			zap.String("channel", a.Channel),
+			zap.String("ts", ts),
+		)
+	}
+}
@@ -0,0 +1,253 @@
+// Package audit records mutating tool calls to an append-only, hash-chained JSONL file.
+//
+// Every entry carries the hash of the previous entry, so removing, reordering or editing
+// a line breaks the chain and is reported by Verify.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// genesisHash is the previous hash of the first entry in a log.
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Entry is a single audit record.
+type Entry struct {
+	Seq         uint64    `json:"seq"`
+	Time        time.Time `json:"time"`
+	Tool        string    `json:"tool"`
+	Caller      string    `json:"caller"`
+	Transport   string    `json:"transport"`
+	Channel     string    `json:"channel,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	TS          string    `json:"ts,omitempty"`
+	Permalink   string    `json:"permalink,omitempty"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash"`
+}
+
+// computeHash hashes the entry with its Hash field cleared.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HashContent returns the hex SHA-256 of content, as stored in Entry.ContentHash.
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends entries to an audit file.
+type Log struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      uint64
+	prevHash string
+}
+
+// Open opens or creates the audit log at path. An existing log is verified first so
+// that new entries continue its chain; a broken log is refused rather than extended.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	last, err := Verify(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("refusing to append to audit log %s: %w", path, err)
+	}
+
+	l := &Log{file: f, prevHash: genesisHash}
+	if last != nil {
+		l.seq = last.Seq
+		l.prevHash = last.Hash
+	}
+	return l, nil
+}
+
+// Record chains e onto the log and writes it. Seq, Time, PrevHash and Hash are set by
+// Record.
+func (l *Log) Record(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	e.Time = time.Now().UTC()
+	e.PrevHash = l.prevHash
+	hash, err := e.computeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	e.Hash = hash
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	l.seq = e.Seq
+	l.prevHash = e.Hash
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// ErrChainBroken is returned by Verify when the log has been tampered with.
+var ErrChainBroken = errors.New("audit chain broken")
+
+// Verify reads a whole log and checks sequence numbers, hashes and chaining.
+// It returns the last entry (nil for an empty log).
+func Verify(r io.Reader) (*Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var last *Entry
+	prevHash := genesisHash
+	line := 0
+	for scanner.Scan() {
+		line++
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return last, fmt.Errorf("%w: line %d: malformed entry: %v", ErrChainBroken, line, err)
+		}
+
+		want := uint64(1)
+		if last != nil {
+			want = last.Seq + 1
+		}
+		if e.Seq != want {
+			return last, fmt.Errorf("%w: line %d: expected seq %d, got %d", ErrChainBroken, line, want, e.Seq)
+		}
+		if e.PrevHash != prevHash {
+			return last, fmt.Errorf("%w: line %d (seq %d): previous hash mismatch", ErrChainBroken, line, e.Seq)
+		}
+		hash, err := e.computeHash()
+		if err != nil {
+			return last, err
+		}
+		if e.Hash != hash {
+			return last, fmt.Errorf("%w: line %d (seq %d): entry hash mismatch", ErrChainBroken, line, e.Seq)
+		}
+
+		prevHash = e.Hash
+		last = &e
+	}
+	if err := scanner.Err(); err != nil {
+		return last, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return last, nil
+}
+
+// VerifyFile verifies the log at path and returns the number of valid entries.
+func VerifyFile(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	last, err := Verify(f)
+	if last == nil {
+		return 0, err
+	}
+	return last.Seq, err
+}
+
+// Event collects details about a mutating call that only its handler knows, such as
+// the target channel and the timestamp Slack assigned.
+type Event struct {
+	mu          sync.Mutex
+	channel     string
+	contentHash string
+	ts          string
+	permalink   string
+}
+
+type eventKey struct{}
+
+// WithEvent attaches ev to ctx.
+func WithEvent(ctx context.Context, ev *Event) context.Context {
+	return context.WithValue(ctx, eventKey{}, ev)
+}
+
+// FromContext returns the event attached to ctx, or nil. All Event methods are safe to
+// call on nil, so handlers do not need to check whether auditing is enabled.
+func FromContext(ctx context.Context) *Event {
+	ev, _ := ctx.Value(eventKey{}).(*Event)
+	return ev
+}
+
+// SetTarget records the channel written to and the hash of the content sent.
+func (ev *Event) SetTarget(channel, content string) {
+	if ev == nil {
+		return
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	ev.channel = channel
+	ev.contentHash = HashContent(content)
+}
+
+// SetResult records the message timestamp and permalink returned by Slack.
+func (ev *Event) SetResult(ts, permalink string) {
+	if ev == nil {
+		return
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	ev.ts = ts
+	ev.permalink = permalink
+}
+
+// Apply copies the collected details into e, keeping e's values where the event has none.
+func (ev *Event) Apply(e *Entry) {
+	if ev == nil {
+		return
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	if ev.channel != "" {
+		e.Channel = ev.channel
+	}
+	if ev.contentHash != "" {
+		e.ContentHash = ev.contentHash
+	}
+	e.TS = ev.ts
+	e.Permalink = ev.permalink
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestLog(t *testing.T, n int) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		require.NoError(t, l.Record(Entry{
+			Tool:        "conversations_add_message",
+			Caller:      "api-key",
+			Transport:   "http",
+			Channel:     "C123",
+			ContentHash: HashContent("hello"),
+			Outcome:     OutcomeSuccess,
+		}))
+	}
+	require.NoError(t, l.Close())
+	return path
+}
+
+func TestUnitAuditLogChain(t *testing.T) {
+	path := writeTestLog(t, 3)
+
+	n, err := VerifyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), n)
+
+	// Reopening continues the existing chain.
+	l, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Record(Entry{Tool: "conversations_add_message", Outcome: OutcomeError, Error: "not_in_channel"}))
+	require.NoError(t, l.Close())
+
+	n, err = VerifyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), n)
+}
+
+func TestUnitAuditLogTampering(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(lines []string) []string
+		wantErr string
+	}{
+		{
+			name: "edited entry",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"channel":"C123"`, `"channel":"C999"`, 1)
+				return lines
+			},
+			wantErr: "entry hash mismatch",
+		},
+		{
+			name: "removed entry",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantErr: "expected seq 2",
+		},
+		{
+			name: "reordered entries",
+			tamper: func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			wantErr: "expected seq 1",
+		},
+		{
+			name: "truncated line",
+			tamper: func(lines []string) []string {
+				lines[2] = lines[2][:10]
+				return lines
+			},
+			wantErr: "malformed entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestLog(t, 3)
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(tt.tamper(lines), "\n")+"\n"), 0o600))
+
+			_, err = VerifyFile(path)
+			require.ErrorIs(t, err, ErrChainBroken)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			_, err = Open(path)
+			assert.ErrorIs(t, err, ErrChainBroken)
+		})
+	}
+}
+
+func TestUnitAuditEvent(t *testing.T) {
+	// Handlers call the event unconditionally; without auditing it is nil.
+	FromContext(context.Background()).SetTarget("C1", "x")
+
+	ev := &Event{}
+	ctx := WithEvent(context.Background(), ev)
+	FromContext(ctx).SetTarget("C42", "hello")
+	FromContext(ctx).SetResult("1700000000.000100", "https://team.slack.com/archives/C42/p1700000000000100")
+
+	entry := Entry{Channel: "#general", ContentHash: "args"}
+	ev.Apply(&entry)
+	assert.Equal(t, "C42", entry.Channel)
+	assert.Equal(t, HashContent("hello"), entry.ContentHash)
+	assert.Equal(t, "1700000000.000100", entry.TS)
+	assert.Equal(t, "https://team.slack.com/archives/C42/p1700000000000100", entry.Permalink)
+}
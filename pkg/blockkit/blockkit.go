@@ -0,0 +1,168 @@
+// Package blockkit parses and validates Block Kit payloads for outgoing messages, so
+// that agents get a precise error for each problem instead of Slack's invalid_blocks.
+package blockkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// BlocksContentType is a JSON array of blocks, or an object with "blocks" and an
+	// optional plain-text "text" fallback.
+	BlocksContentType = "application/vnd.slack.blocks+json"
+	// AttachmentsContentType is a JSON array of legacy attachments, or an object with
+	// "attachments" and an optional "text".
+	AttachmentsContentType = "application/vnd.slack.attachments+json"
+
+	maxBlocks      = 50
+	maxAttachments = 100
+	// maxProblems caps how many problems a single error reports.
+	maxProblems = 10
+)
+
+// Message is a validated payload, ready for slack.MsgOptionBlocks or
+// slack.MsgOptionAttachments.
+type Message struct {
+	Blocks      []slack.Block
+	Attachments []slack.Attachment
+	// Text is the plain-text fallback shown in notifications.
+	Text string
+}
+
+// ParseBlocks validates a BlocksContentType payload. The blocks are posted exactly as
+// given, including block types the Slack client library does not know yet.
+func ParseBlocks(payload string) (*Message, error) {
+	raws, fallback, err := decode(payload, "blocks")
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.blocks("blocks", raws, maxBlocks)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{Text: fallback}
+	typed := make([]slack.Block, 0, len(raws))
+	for _, raw := range raws {
+		b := rawBlock{raw: raw}
+		var head struct {
+			Type    string `json:"type"`
+			BlockID string `json:"block_id"`
+		}
+		_ = json.Unmarshal(raw, &head)
+		b.typ, b.id = slack.MessageBlockType(head.Type), head.BlockID
+		msg.Blocks = append(msg.Blocks, b)
+
+		var one slack.Blocks
+		if err := json.Unmarshal(append(append([]byte("["), raw...), ']'), &one); err == nil {
+			typed = append(typed, one.BlockSet...)
+		}
+	}
+	if msg.Text == "" {
+		msg.Text = text.BlocksToText(typed)
+	}
+	return msg, nil
+}
+
+// ParseAttachments validates an AttachmentsContentType payload and fills in a fallback
+// for attachments that have none.
+func ParseAttachments(payload string) (*Message, error) {
+	raws, fallback, err := decode(payload, "attachments")
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.attachments("attachments", raws)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{Text: fallback}
+	var summaries []string
+	for i, raw := range raws {
+		var att slack.Attachment
+		if err := json.Unmarshal(raw, &att); err != nil {
+			return nil, fmt.Errorf("attachments[%d]: %w", i, err)
+		}
+		if att.Fallback == "" {
+			att.Fallback = firstNonEmpty(att.Title, att.Text, att.Pretext, text.BlocksToText(att.Blocks.BlockSet))
+		}
+		summaries = append(summaries, att.Fallback)
+		msg.Attachments = append(msg.Attachments, att)
+	}
+	if msg.Text == "" {
+		msg.Text = strings.Join(summaries, "\n")
+	}
+	return msg, nil
+}
+
+// decode accepts either a JSON array or an object holding the array under key, with
+// an optional "text" fallback.
+func decode(payload, key string) ([]json.RawMessage, string, error) {
+	payload = strings.TrimSpace(payload)
+	if strings.HasPrefix(payload, "[") {
+		var raws []json.RawMessage
+		if err := json.Unmarshal([]byte(payload), &raws); err != nil {
+			return nil, "", fmt.Errorf("payload is not a valid JSON array of %s: %w", key, err)
+		}
+		return raws, "", nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &obj); err != nil {
+		return nil, "", fmt.Errorf("payload must be a JSON array of %s or an object with a %q array: %w", key, key, err)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(obj[key], &raws); err != nil || obj[key] == nil {
+		return nil, "", fmt.Errorf("payload object must have a %q array", key)
+	}
+	var fallback string
+	if t, ok := obj["text"]; ok {
+		if err := json.Unmarshal(t, &fallback); err != nil {
+			return nil, "", errors.New(`payload "text" must be a string`)
+		}
+	}
+	return raws, fallback, nil
+}
+
+// rawBlock posts a validated block unchanged.
+type rawBlock struct {
+	typ slack.MessageBlockType
+	id  string
+	raw json.RawMessage
+}
+
+func (b rawBlock) BlockType() slack.MessageBlockType { return b.typ }
+func (b rawBlock) ID() string                        { return b.id }
+
+func (b rawBlock) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.raw); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func length(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,98 @@
+package blockkit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validBlocks = `[
+  {"type": "header", "text": {"type": "plain_text", "text": "Deploy finished"}},
+  {"type": "section", "block_id": "summary",
+   "fields": [{"type": "mrkdwn", "text": "*Service*\napi"}, {"type": "mrkdwn", "text": "*Version*\nv2.1"}],
+   "accessory": {"type": "button", "text": {"type": "plain_text", "text": "Open"}, "url": "https://ci.example.com/1", "action_id": "open"}},
+  {"type": "context", "elements": [{"type": "mrkdwn", "text": "took 4m"}, {"type": "image", "image_url": "https://example.com/i.png", "alt_text": "ci"}]},
+  {"type": "table", "rows": [[{"type": "raw_text", "text": "region"}, {"type": "raw_text", "text": "status"}], [{"type": "raw_text", "text": "eu"}, {"type": "raw_text", "text": "ok"}]]},
+  {"type": "divider"},
+  {"type": "actions", "elements": [{"type": "button", "text": {"type": "plain_text", "text": "Roll back"}, "style": "danger", "value": "rollback"}]}
+]`
+
+func TestUnitParseBlocks(t *testing.T) {
+	msg, err := ParseBlocks(validBlocks)
+	require.NoError(t, err)
+	require.Len(t, msg.Blocks, 6)
+	assert.Equal(t, "summary", msg.Blocks[1].ID())
+	assert.Equal(t, "table", string(msg.Blocks[3].BlockType()))
+	assert.True(t, strings.HasPrefix(msg.Text, "Deploy finished\n\n*Service*\napi\n*Version*\nv2.1"), msg.Text)
+
+	// Blocks are posted unchanged, including types the Slack library does not model.
+	b, err := json.Marshal(msg.Blocks[3])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "table", "rows": [[{"type": "raw_text", "text": "region"}, {"type": "raw_text", "text": "status"}], [{"type": "raw_text", "text": "eu"}, {"type": "raw_text", "text": "ok"}]]}`, string(b))
+
+	msg, err = ParseBlocks(`{"blocks": [{"type": "divider"}], "text": "custom fallback"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "custom fallback", msg.Text)
+}
+
+func TestUnitParseBlocksErrors(t *testing.T) {
+	long := strings.Repeat("x", 151)
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{"not json", `[{"type": }]`, []string{"not a valid JSON array of blocks"}},
+		{"object without blocks", `{"text": "hi"}`, []string{`must have a "blocks" array`}},
+		{"empty", `[]`, []string{"blocks: must contain at least one block"}},
+		{"too many", "[" + strings.TrimSuffix(strings.Repeat(`{"type": "divider"},`, 51), ",") + "]", []string{"blocks: has 51 blocks, at most 50 are allowed"}},
+		{"unknown type", `[{"type": "modal"}]`, []string{`blocks[0].type: "modal" is not a block type allowed in messages`}},
+		{"header too long", `[{"type": "header", "text": {"type": "plain_text", "text": "` + long + `"}}]`, []string{"blocks[0].text.text: must be at most 150 characters, got 151"}},
+		{"header mrkdwn", `[{"type": "header", "text": {"type": "mrkdwn", "text": "*x*"}}]`, []string{`blocks[0].text.type: must be "plain_text" here`}},
+		{"empty section", `[{"type": "section"}]`, []string{"blocks[0]: section needs text or fields"}},
+		{"too many fields", `[{"type": "section", "fields": [` + strings.TrimSuffix(strings.Repeat(`{"type": "mrkdwn", "text": "f"},`, 11), ",") + `]}]`, []string{"blocks[0].fields: has 11 items, at most 10 are allowed"}},
+		{"bad accessory", `[{"type": "section", "text": {"type": "mrkdwn", "text": "x"}, "accessory": {"type": "plain_text_input"}}]`, []string{`blocks[0].accessory.type: "plain_text_input" is not allowed here`}},
+		{"button without text", `[{"type": "actions", "elements": [{"type": "button", "style": "blue"}]}]`, []string{"blocks[0].elements[0].text: is required", `blocks[0].elements[0].style: must be "primary" or "danger"`}},
+		{"image without alt", `[{"type": "image", "image_url": "https://example.com/a.png"}]`, []string{"blocks[0].alt_text: is required"}},
+		{"duplicate block id", `[{"type": "divider", "block_id": "a"}, {"type": "divider", "block_id": "a"}]`, []string{`blocks[1].block_id: "a" is already used by blocks[0]`}},
+		{"two tables", `[{"type": "table", "rows": [[{"type": "raw_text", "text": "a"}]]}, {"type": "table", "rows": [[{"type": "raw_text", "text": "b"}]]}]`, []string{"blocks[1]: a message may contain only one table"}},
+		{"bad table cell", `[{"type": "table", "rows": [["a"]]}]`, []string{"blocks[0].rows[0][0]: must be a cell object"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBlocks(tt.payload)
+			require.Error(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestUnitParseBlocksCapsProblems(t *testing.T) {
+	_, err := ParseBlocks("[" + strings.TrimSuffix(strings.Repeat(`{"type": "nope"},`, 12), ",") + "]")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "and 2 more")
+	assert.NotContains(t, err.Error(), "blocks[10]")
+}
+
+func TestUnitParseAttachments(t *testing.T) {
+	msg, err := ParseAttachments(`[
+	  {"color": "#2eb886", "title": "Build passed", "fields": [{"title": "Branch", "value": "main", "short": true}]},
+	  {"color": "danger", "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": "1 flaky test"}}]}
+	]`)
+	require.NoError(t, err)
+	require.Len(t, msg.Attachments, 2)
+	assert.Equal(t, "Build passed", msg.Attachments[0].Fallback)
+	assert.Equal(t, "1 flaky test", msg.Attachments[1].Fallback)
+	assert.Equal(t, "Build passed\n1 flaky test", msg.Text)
+
+	_, err = ParseAttachments(`[{"color": "good"}, {"text": 5}, {"blocks": [{"type": "header"}]}]`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "attachments[0]: attachment needs text, title, fields or blocks")
+	assert.Contains(t, err.Error(), "attachments[1].text: must be a string")
+	assert.Contains(t, err.Error(), "attachments[2].blocks[0].text: is required")
+}
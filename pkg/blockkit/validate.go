@@ -0,0 +1,441 @@
+package blockkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Limits from the Block Kit reference for blocks in messages.
+const (
+	maxBlockID          = 255
+	maxActionID         = 255
+	maxURL              = 3000
+	maxSectionText      = 3000
+	maxSectionFields    = 10
+	maxFieldText        = 2000
+	maxHeaderText       = 150
+	maxContextElements  = 10
+	maxActionsElements  = 25
+	maxAltText          = 2000
+	maxImageTitle       = 2000
+	maxMarkdownText     = 12000
+	maxTableRows        = 100
+	maxTableCells       = 20
+	maxButtonText       = 75
+	maxButtonValue      = 2000
+	maxPlaceholder      = 150
+	maxOptions          = 100
+	maxOverflowOptions  = 5
+	maxChoiceOptions    = 10
+	maxOptionText       = 75
+	maxOptionValue      = 150
+	maxInputLabel       = 2000
+	maxVideoTitle       = 200
+	maxAttachmentFields = 100
+)
+
+var (
+	messageBlocks = []string{"actions", "context", "divider", "file", "header", "image", "input", "markdown", "rich_text", "section", "table", "video"}
+
+	interactiveElements = []string{
+		"button", "checkboxes", "datepicker", "datetimepicker", "overflow", "radio_buttons", "timepicker", "workflow_button",
+		"static_select", "external_select", "users_select", "conversations_select", "channels_select",
+		"multi_static_select", "multi_external_select", "multi_users_select", "multi_conversations_select", "multi_channels_select",
+	}
+	sectionAccessories = append(slices.Clone(interactiveElements), "image")
+	inputElements      = append(slices.Clone(interactiveElements), "plain_text_input", "email_text_input", "url_text_input", "number_input", "rich_text_input", "file_input")
+	richTextElements   = []string{"rich_text_section", "rich_text_list", "rich_text_preformatted", "rich_text_quote"}
+)
+
+// validator collects every problem of a payload, each prefixed with its JSON path.
+type validator struct {
+	problems     []string
+	blockIDs     map[string]string
+	tables       int
+	markdownText int
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	problems := v.problems
+	if len(problems) > maxProblems {
+		problems = append(problems[:maxProblems:maxProblems], fmt.Sprintf("and %d more", len(v.problems)-maxProblems))
+	}
+	return errors.New("invalid Block Kit payload: " + strings.Join(problems, "; "))
+}
+
+func (v *validator) blocks(path string, raws []json.RawMessage, max int) {
+	if len(raws) == 0 {
+		v.addf(path, "must contain at least one block")
+		return
+	}
+	if len(raws) > max {
+		v.addf(path, "has %d blocks, at most %d are allowed", len(raws), max)
+	}
+	for i, raw := range raws {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		var block map[string]any
+		if err := json.Unmarshal(raw, &block); err != nil || block == nil {
+			v.addf(p, "must be a JSON object")
+			continue
+		}
+		v.block(p, block)
+	}
+}
+
+func (v *validator) block(path string, b map[string]any) {
+	typ, _ := b["type"].(string)
+	if typ == "" {
+		v.addf(path+".type", "is required")
+		return
+	}
+	if !slices.Contains(messageBlocks, typ) {
+		v.addf(path+".type", "%q is not a block type allowed in messages, use one of %s", typ, strings.Join(messageBlocks, ", "))
+		return
+	}
+	if id, ok := b["block_id"]; ok {
+		s, isString := id.(string)
+		switch {
+		case !isString || s == "":
+			v.addf(path+".block_id", "must be a non-empty string")
+		case length(s) > maxBlockID:
+			v.addf(path+".block_id", "must be at most %d characters", maxBlockID)
+		default:
+			if v.blockIDs == nil {
+				v.blockIDs = make(map[string]string)
+			}
+			if first, dup := v.blockIDs[s]; dup {
+				v.addf(path+".block_id", "%q is already used by %s", s, first)
+			}
+			v.blockIDs[s] = path
+		}
+	}
+
+	switch typ {
+	case "section":
+		_, hasText := b["text"]
+		fields, hasFields := b["fields"]
+		if !hasText && !hasFields {
+			v.addf(path, "section needs text or fields")
+		}
+		if hasText {
+			v.textObject(path+".text", b["text"], false, maxSectionText)
+		}
+		if hasFields {
+			items := v.array(path+".fields", fields, 1, maxSectionFields)
+			for i, f := range items {
+				v.textObject(fmt.Sprintf("%s.fields[%d]", path, i), f, false, maxFieldText)
+			}
+		}
+		if acc, ok := b["accessory"]; ok {
+			v.element(path+".accessory", acc, sectionAccessories)
+		}
+	case "header":
+		v.required(path, b, "text")
+		if t, ok := b["text"]; ok {
+			v.textObject(path+".text", t, true, maxHeaderText)
+		}
+	case "context":
+		for i, el := range v.array(path+".elements", b["elements"], 1, maxContextElements) {
+			p := fmt.Sprintf("%s.elements[%d]", path, i)
+			if m, ok := el.(map[string]any); ok && m["type"] == "image" {
+				v.image(p, m)
+				continue
+			}
+			v.textObject(p, el, false, 0)
+		}
+	case "actions":
+		for i, el := range v.array(path+".elements", b["elements"], 1, maxActionsElements) {
+			v.element(fmt.Sprintf("%s.elements[%d]", path, i), el, interactiveElements)
+		}
+	case "image":
+		v.image(path, b)
+		if t, ok := b["title"]; ok {
+			v.textObject(path+".title", t, true, maxImageTitle)
+		}
+	case "rich_text":
+		for i, el := range v.array(path+".elements", b["elements"], 1, 0) {
+			p := fmt.Sprintf("%s.elements[%d]", path, i)
+			m, ok := el.(map[string]any)
+			if !ok {
+				v.addf(p, "must be an object")
+				continue
+			}
+			if t, _ := m["type"].(string); !slices.Contains(richTextElements, t) {
+				v.addf(p+".type", "%q is not a rich_text element, use one of %s", m["type"], strings.Join(richTextElements, ", "))
+			}
+		}
+	case "markdown":
+		s := v.string(path+".text", b["text"], true, maxMarkdownText)
+		v.markdownText += length(s)
+		if v.markdownText > maxMarkdownText {
+			v.addf(path+".text", "markdown blocks may hold at most %d characters in total", maxMarkdownText)
+		}
+	case "table":
+		v.tables++
+		if v.tables > 1 {
+			v.addf(path, "a message may contain only one table")
+		}
+		for i, row := range v.array(path+".rows", b["rows"], 1, maxTableRows) {
+			p := fmt.Sprintf("%s.rows[%d]", path, i)
+			for j, cell := range v.array(p, row, 1, maxTableCells) {
+				cp := fmt.Sprintf("%s[%d]", p, j)
+				m, ok := cell.(map[string]any)
+				if !ok {
+					v.addf(cp, "must be a cell object")
+					continue
+				}
+				switch m["type"] {
+				case "raw_text":
+					v.string(cp+".text", m["text"], true, 0)
+				case "rich_text":
+					v.array(cp+".elements", m["elements"], 1, 0)
+				default:
+					v.addf(cp+".type", "must be raw_text or rich_text")
+				}
+			}
+		}
+	case "video":
+		for _, key := range []string{"alt_text", "thumbnail_url", "video_url"} {
+			v.string(path+"."+key, b[key], true, maxURL)
+		}
+		v.required(path, b, "title")
+		if t, ok := b["title"]; ok {
+			v.textObject(path+".title", t, true, maxVideoTitle)
+		}
+	case "file":
+		v.string(path+".external_id", b["external_id"], true, 0)
+		if b["source"] != "remote" {
+			v.addf(path+".source", `must be "remote"`)
+		}
+	case "input":
+		v.required(path, b, "label")
+		if l, ok := b["label"]; ok {
+			v.textObject(path+".label", l, true, maxInputLabel)
+		}
+		v.required(path, b, "element")
+		if el, ok := b["element"]; ok {
+			v.element(path+".element", el, inputElements)
+		}
+	}
+}
+
+func (v *validator) element(path string, el any, allowed []string) {
+	m, ok := el.(map[string]any)
+	if !ok {
+		v.addf(path, "must be an element object")
+		return
+	}
+	typ, _ := m["type"].(string)
+	if !slices.Contains(allowed, typ) {
+		sorted := slices.Clone(allowed)
+		sort.Strings(sorted)
+		v.addf(path+".type", "%q is not allowed here, use one of %s", typ, strings.Join(sorted, ", "))
+		return
+	}
+	if id, ok := m["action_id"]; ok {
+		v.string(path+".action_id", id, true, maxActionID)
+	}
+
+	switch typ {
+	case "button":
+		v.required(path, m, "text")
+		if t, ok := m["text"]; ok {
+			v.textObject(path+".text", t, true, maxButtonText)
+		}
+		if u, ok := m["url"]; ok {
+			v.string(path+".url", u, true, maxURL)
+		}
+		if val, ok := m["value"]; ok {
+			v.string(path+".value", val, false, maxButtonValue)
+		}
+		if style, ok := m["style"]; ok && style != "primary" && style != "danger" {
+			v.addf(path+".style", `must be "primary" or "danger"`)
+		}
+	case "image":
+		v.image(path, m)
+	case "overflow":
+		v.options(path, m["options"], maxOverflowOptions)
+	case "checkboxes", "radio_buttons":
+		v.options(path, m["options"], maxChoiceOptions)
+	case "static_select", "multi_static_select":
+		_, hasOptions := m["options"]
+		_, hasGroups := m["option_groups"]
+		switch {
+		case hasOptions && hasGroups:
+			v.addf(path, "use either options or option_groups, not both")
+		case hasGroups:
+			for i, g := range v.array(path+".option_groups", m["option_groups"], 1, maxOptions) {
+				gm, _ := g.(map[string]any)
+				v.options(fmt.Sprintf("%s.option_groups[%d]", path, i), gm["options"], maxOptions)
+			}
+		default:
+			v.options(path, m["options"], maxOptions)
+		}
+	}
+	if p, ok := m["placeholder"]; ok {
+		v.textObject(path+".placeholder", p, true, maxPlaceholder)
+	}
+}
+
+func (v *validator) options(path string, options any, max int) {
+	for i, o := range v.array(path+".options", options, 1, max) {
+		p := fmt.Sprintf("%s.options[%d]", path, i)
+		m, ok := o.(map[string]any)
+		if !ok {
+			v.addf(p, "must be an option object")
+			continue
+		}
+		v.required(p, m, "text")
+		if t, ok := m["text"]; ok {
+			v.textObject(p+".text", t, false, maxOptionText)
+		}
+		v.string(p+".value", m["value"], true, maxOptionValue)
+	}
+}
+
+func (v *validator) image(path string, m map[string]any) {
+	_, hasURL := m["image_url"]
+	_, hasFile := m["slack_file"]
+	switch {
+	case hasURL && hasFile:
+		v.addf(path, "use either image_url or slack_file, not both")
+	case hasURL:
+		v.string(path+".image_url", m["image_url"], true, maxURL)
+	case !hasFile:
+		v.addf(path, "image needs image_url or slack_file")
+	}
+	v.string(path+".alt_text", m["alt_text"], true, maxAltText)
+}
+
+// textObject checks a plain_text or mrkdwn text object. max 0 means no limit.
+func (v *validator) textObject(path string, val any, plainOnly bool, max int) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		v.addf(path, `must be a text object like {"type": "plain_text", "text": "..."}`)
+		return
+	}
+	switch m["type"] {
+	case "plain_text":
+	case "mrkdwn":
+		if plainOnly {
+			v.addf(path+".type", `must be "plain_text" here`)
+		}
+	default:
+		if plainOnly {
+			v.addf(path+".type", `must be "plain_text"`)
+		} else {
+			v.addf(path+".type", `must be "plain_text" or "mrkdwn"`)
+		}
+	}
+	v.string(path+".text", m["text"], true, max)
+}
+
+// string checks a string value and returns it. max 0 means no limit.
+func (v *validator) string(path string, val any, required bool, max int) string {
+	if val == nil {
+		if required {
+			v.addf(path, "is required")
+		}
+		return ""
+	}
+	s, ok := val.(string)
+	switch {
+	case !ok:
+		v.addf(path, "must be a string")
+	case required && s == "":
+		v.addf(path, "must not be empty")
+	case max > 0 && length(s) > max:
+		v.addf(path, "must be at most %d characters, got %d", max, length(s))
+	}
+	return s
+}
+
+// array checks an array value and returns its items. max 0 means no limit.
+func (v *validator) array(path string, val any, min, max int) []any {
+	items, ok := val.([]any)
+	switch {
+	case val == nil:
+		v.addf(path, "is required")
+	case !ok:
+		v.addf(path, "must be an array")
+	case len(items) < min:
+		v.addf(path, "must have at least %d item(s)", min)
+	case max > 0 && len(items) > max:
+		v.addf(path, "has %d items, at most %d are allowed", len(items), max)
+	}
+	return items
+}
+
+func (v *validator) required(path string, m map[string]any, key string) {
+	if _, ok := m[key]; !ok {
+		v.addf(path+"."+key, "is required")
+	}
+}
+
+func (v *validator) attachments(path string, raws []json.RawMessage) {
+	if len(raws) == 0 {
+		v.addf(path, "must contain at least one attachment")
+		return
+	}
+	if len(raws) > maxAttachments {
+		v.addf(path, "has %d attachments, at most %d are allowed", len(raws), maxAttachments)
+	}
+	for i, raw := range raws {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		var att map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &att); err != nil || att == nil {
+			v.addf(p, "must be a JSON object")
+			continue
+		}
+
+		hasContent := false
+		for _, key := range []string{"text", "title", "pretext", "fallback", "author_name", "image_url", "thumb_url", "fields", "blocks"} {
+			if _, ok := att[key]; ok {
+				hasContent = true
+			}
+		}
+		if !hasContent {
+			v.addf(p, "attachment needs text, title, fields or blocks")
+		}
+		for _, key := range []string{"text", "title", "pretext", "fallback", "color", "author_name", "footer"} {
+			if raw, ok := att[key]; ok {
+				var s string
+				if json.Unmarshal(raw, &s) != nil {
+					v.addf(p+"."+key, "must be a string")
+				}
+			}
+		}
+		if raw, ok := att["fields"]; ok {
+			var fields any
+			_ = json.Unmarshal(raw, &fields)
+			for j, f := range v.array(p+".fields", fields, 0, maxAttachmentFields) {
+				fm, ok := f.(map[string]any)
+				if !ok {
+					v.addf(fmt.Sprintf("%s.fields[%d]", p, j), "must be an object with title and value")
+					continue
+				}
+				v.string(fmt.Sprintf("%s.fields[%d].title", p, j), fm["title"], false, 0)
+				v.string(fmt.Sprintf("%s.fields[%d].value", p, j), fm["value"], false, 0)
+			}
+		}
+		if raw, ok := att["blocks"]; ok {
+			var blocks []json.RawMessage
+			if err := json.Unmarshal(raw, &blocks); err != nil {
+				v.addf(p+".blocks", "must be an array of blocks")
+				continue
+			}
+			v.blocks(p+".blocks", blocks, maxBlocks)
+		}
+	}
+}
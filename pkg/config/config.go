@@ -0,0 +1,615 @@
+// Package config holds the server configuration. Settings are read from an optional
+// YAML or TOML file and overridden by SLACK_MCP_* environment variables, so existing
+// env-only deployments keep working unchanged.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete server configuration. Every field carries the environment
+// variable that overrides it; fields tagged reload:"live" take effect when the config
+// file changes, all others are read once at startup.
+type Config struct {
+	Slack       Slack       `yaml:"slack" toml:"slack"`
+	Server      Server      `yaml:"server" toml:"server"`
+	OAuth       OAuth       `yaml:"oauth" toml:"oauth"`
+	Tools       Tools       `yaml:"tools" toml:"tools"`
+	AddMessage  AddMessage  `yaml:"add_message" toml:"add_message"`
+	FilesUpload FilesUpload `yaml:"files_upload" toml:"files_upload"`
+	Images      Images      `yaml:"images" toml:"images"`
+	ReadPolicy  ReadPolicy  `yaml:"read_policy" toml:"read_policy"`
+	SecretScan  SecretScan  `yaml:"secret_scan" toml:"secret_scan"`
+	Redaction   Redaction   `yaml:"redaction" toml:"redaction"`
+	Confirm     Confirm     `yaml:"confirm" toml:"confirm"`
+	Approval    Approval    `yaml:"approval" toml:"approval"`
+	Team        Team        `yaml:"team" toml:"team"`
+	Network     Network     `yaml:"network" toml:"network"`
+	Log         Log         `yaml:"log" toml:"log"`
+}
+
+// Slack holds the workspace credentials and cache locations.
+type Slack struct {
+	XoxpToken     string `yaml:"xoxp_token" toml:"xoxp_token" env:"SLACK_MCP_XOXP_TOKEN"`
+	XoxbToken     string `yaml:"xoxb_token" toml:"xoxb_token" env:"SLACK_MCP_XOXB_TOKEN"`
+	XoxcToken     string `yaml:"xoxc_token" toml:"xoxc_token" env:"SLACK_MCP_XOXC_TOKEN"`
+	XoxdToken     string `yaml:"xoxd_token" toml:"xoxd_token" env:"SLACK_MCP_XOXD_TOKEN"`
+	UsersCache    string `yaml:"users_cache" toml:"users_cache" env:"SLACK_MCP_USERS_CACHE"`
+	ChannelsCache string `yaml:"channels_cache" toml:"channels_cache" env:"SLACK_MCP_CHANNELS_CACHE"`
+}
+
+// IsDemo reports whether demo credentials are configured, in which case the server
+// never talks to Slack.
+func (s Slack) IsDemo() bool {
+	return s.XoxpToken == "demo" || (s.XoxcToken == "demo" && s.XoxdToken == "demo")
+}
+
+// Server configures the MCP listeners.
+type Server struct {
+	Host            string        `yaml:"host" toml:"host" env:"SLACK_MCP_HOST"`
+	Port            int           `yaml:"port" toml:"port" env:"SLACK_MCP_PORT"`
+	MetricsPort     int           `yaml:"metrics_port" toml:"metrics_port" env:"SLACK_MCP_METRICS_PORT"`
+	APIKey          string        `yaml:"api_key" toml:"api_key" env:"SLACK_MCP_API_KEY" reload:"live"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SLACK_MCP_SHUTDOWN_TIMEOUT"`
+	AuditLog        string        `yaml:"audit_log" toml:"audit_log" env:"SLACK_MCP_AUDIT_LOG"`
+	TLS             TLS           `yaml:"tls" toml:"tls"`
+}
+
+// TLS configures HTTPS for the SSE and HTTP transports.
+type TLS struct {
+	Cert     string `yaml:"cert" toml:"cert" env:"SLACK_MCP_TLS_CERT"`
+	Key      string `yaml:"key" toml:"key" env:"SLACK_MCP_TLS_KEY"`
+	ClientCA string `yaml:"client_ca" toml:"client_ca" env:"SLACK_MCP_TLS_CLIENT_CA"`
+}
+
+// OAuth configures bearer token authorization for the HTTP transport.
+type OAuth struct {
+	Issuer           string `yaml:"issuer" toml:"issuer" env:"SLACK_MCP_OAUTH_ISSUER"`
+	Resource         string `yaml:"resource" toml:"resource" env:"SLACK_MCP_OAUTH_RESOURCE"`
+	Audience         string `yaml:"audience" toml:"audience" env:"SLACK_MCP_OAUTH_AUDIENCE"`
+	JWKSURL          string `yaml:"jwks_url" toml:"jwks_url" env:"SLACK_MCP_OAUTH_JWKS_URL"`
+	IntrospectionURL string `yaml:"introspection_url" toml:"introspection_url" env:"SLACK_MCP_OAUTH_INTROSPECTION_URL"`
+	ClientID         string `yaml:"client_id" toml:"client_id" env:"SLACK_MCP_OAUTH_CLIENT_ID"`
+	ClientSecret     string `yaml:"client_secret" toml:"client_secret" env:"SLACK_MCP_OAUTH_CLIENT_SECRET"`
+	ReadScope        string `yaml:"read_scope" toml:"read_scope" env:"SLACK_MCP_OAUTH_READ_SCOPE"`
+	WriteScope       string `yaml:"write_scope" toml:"write_scope" env:"SLACK_MCP_OAUTH_WRITE_SCOPE"`
+}
+
+// Tools restricts which tools are registered. At most one of Enabled and Disabled may
+// be set; ReadOnly additionally drops every tool not annotated as read-only.
+type Tools struct {
+	Enabled  []string `yaml:"enabled" toml:"enabled" env:"SLACK_MCP_ENABLED_TOOLS"`
+	Disabled []string `yaml:"disabled" toml:"disabled" env:"SLACK_MCP_DISABLED_TOOLS"`
+	ReadOnly bool     `yaml:"read_only" toml:"read_only" env:"SLACK_MCP_READ_ONLY"`
+}
+
+// AddMessage configures the conversations_add_message tool.
+type AddMessage struct {
+	// Tool is the channel policy: empty disables the tool, "true" or "1" allows every
+	// channel, otherwise a comma-separated list of channel IDs to allow, or of
+	// "!"-prefixed IDs to deny.
+	Tool string `yaml:"tool" toml:"tool" env:"SLACK_MCP_ADD_MESSAGE_TOOL" reload:"live"`
+	// Mark marks posted messages as read.
+	Mark bool `yaml:"mark" toml:"mark" env:"SLACK_MCP_ADD_MESSAGE_MARK" reload:"live"`
+	// Unfurling is "true" to unfurl all links, or a comma-separated list of domains.
+	Unfurling string `yaml:"unfurling" toml:"unfurling" env:"SLACK_MCP_ADD_MESSAGE_UNFURLING" reload:"live"`
+	// Broadcast decides what @here, @channel and @everyone do: "text" sends them as
+	// plain words that notify nobody, "allow" turns them into real broadcasts and
+	// "deny" refuses such messages.
+	Broadcast string `yaml:"broadcast" toml:"broadcast" env:"SLACK_MCP_ADD_MESSAGE_BROADCAST" reload:"live"`
+	// OpenDM lets the tool open new DMs and group DMs with users it has no
+	// conversation with yet.
+	OpenDM bool `yaml:"open_dm" toml:"open_dm" env:"SLACK_MCP_ADD_MESSAGE_OPEN_DM" reload:"live"`
+}
+
+// FilesUpload configures the files_upload tool.
+type FilesUpload struct {
+	// Tool is the channel policy, in the same format as AddMessage.Tool.
+	Tool string `yaml:"tool" toml:"tool" env:"SLACK_MCP_FILES_UPLOAD_TOOL" reload:"live"`
+	// Dir is the only directory whose files may be uploaded by path. Empty allows
+	// base64 content only.
+	Dir string `yaml:"dir" toml:"dir" env:"SLACK_MCP_FILES_UPLOAD_DIR" reload:"live"`
+}
+
+// Images configures how images are prepared before they are returned inline.
+type Images struct {
+	// MaxDimension is the longest edge, in pixels, images are downscaled to.
+	MaxDimension int `yaml:"max_dimension" toml:"max_dimension" env:"SLACK_MCP_IMAGE_MAX_DIMENSION" reload:"live"`
+}
+
+// ReadPolicy restricts which conversations tools and resources may read. Entries are
+// channel IDs, names ("#hr-private", "@alice"), glob patterns over either ("#hr-*"),
+// or conversation types ("type:im", "type:mpim", "type:private", "type:public").
+// Deny wins over allow; a non-empty allow list denies everything it does not match.
+type ReadPolicy struct {
+	Allow []string `yaml:"allow" toml:"allow" env:"SLACK_MCP_READ_ALLOW" reload:"live"`
+	Deny  []string `yaml:"deny" toml:"deny" env:"SLACK_MCP_READ_DENY" reload:"live"`
+}
+
+// SecretScan configures the scanner that checks outgoing messages for secrets and
+// personal data before they are posted.
+type SecretScan struct {
+	// Action applies to channels without a rule: "block", "redact" or "allow".
+	Action string `yaml:"action" toml:"action" env:"SLACK_MCP_SECRET_SCAN" reload:"live"`
+	// Channels are "<entry>=<action>" rules, where entry uses the read_policy syntax.
+	// The first matching rule wins.
+	Channels []string `yaml:"channels" toml:"channels" env:"SLACK_MCP_SECRET_SCAN_CHANNELS" reload:"live"`
+	// Patterns are extra regular expressions to detect. In the environment variable they
+	// are comma-separated, so patterns containing commas belong in the config file.
+	Patterns []string `yaml:"patterns" toml:"patterns" env:"SLACK_MCP_SECRET_SCAN_PATTERNS" reload:"live"`
+}
+
+// Redaction masks emails, phone numbers, secrets and custom patterns in messages before
+// they are returned to the model.
+type Redaction struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"SLACK_MCP_REDACT" reload:"live"`
+	// Channels limits redaction to matching conversations, using the read_policy syntax.
+	// Empty means every conversation.
+	Channels []string `yaml:"channels" toml:"channels" env:"SLACK_MCP_REDACT_CHANNELS" reload:"live"`
+	// Patterns are extra regular expressions to mask.
+	Patterns []string `yaml:"patterns" toml:"patterns" env:"SLACK_MCP_REDACT_PATTERNS" reload:"live"`
+}
+
+// Confirm asks the user to confirm messages before they are posted, through MCP
+// elicitation in clients that support it.
+type Confirm struct {
+	// Action applies to channels without a rule: "confirm" or "skip".
+	Action string `yaml:"action" toml:"action" env:"SLACK_MCP_CONFIRM" reload:"live"`
+	// Channels are "<entry>=<action>" rules, where entry uses the read_policy syntax.
+	// The first matching rule wins.
+	Channels []string `yaml:"channels" toml:"channels" env:"SLACK_MCP_CONFIRM_CHANNELS" reload:"live"`
+	// Fallback applies when a message needs confirmation but the client cannot ask the
+	// user: "refuse" or "post".
+	Fallback string `yaml:"fallback" toml:"fallback" env:"SLACK_MCP_CONFIRM_FALLBACK" reload:"live"`
+}
+
+// Approval queues write tool calls for human review instead of running them.
+type Approval struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"SLACK_MCP_APPROVAL"`
+	// Dir is the queue directory shared with the approvals CLI. Empty means the default
+	// under the user cache directory.
+	Dir string `yaml:"dir" toml:"dir" env:"SLACK_MCP_APPROVAL_DIR"`
+	// ReviewerKey signs reviewers in to the /approvals page, which is not served without
+	// it. It must differ from the MCP API key, which the agent holds.
+	ReviewerKey string `yaml:"reviewer_key" toml:"reviewer_key" env:"SLACK_MCP_APPROVAL_REVIEWER_KEY"`
+}
+
+// Team configures the get_team_context tool.
+type Team struct {
+	Name             string   `yaml:"name" toml:"name" env:"SLACK_MCP_TEAM_NAME" reload:"live"`
+	PriorityChannels []string `yaml:"priority_channels" toml:"priority_channels" env:"SLACK_MCP_PRIORITY_CHANNELS" reload:"live"`
+	PriorityUsers    []string `yaml:"priority_users" toml:"priority_users" env:"SLACK_MCP_PRIORITY_USERS" reload:"live"`
+}
+
+// Network configures the HTTP client used to talk to Slack.
+type Network struct {
+	Proxy            string `yaml:"proxy" toml:"proxy" env:"SLACK_MCP_PROXY"`
+	UserAgent        string `yaml:"user_agent" toml:"user_agent" env:"SLACK_MCP_USER_AGENT"`
+	CustomTLS        bool   `yaml:"custom_tls" toml:"custom_tls" env:"SLACK_MCP_CUSTOM_TLS"`
+	ServerCA         string `yaml:"server_ca" toml:"server_ca" env:"SLACK_MCP_SERVER_CA"`
+	ServerCAToolkit  bool   `yaml:"server_ca_toolkit" toml:"server_ca_toolkit" env:"SLACK_MCP_SERVER_CA_TOOLKIT"`
+	ServerCAInsecure bool   `yaml:"server_ca_insecure" toml:"server_ca_insecure" env:"SLACK_MCP_SERVER_CA_INSECURE"`
+}
+
+// Log configures the server log output.
+type Log struct {
+	Level  string `yaml:"level" toml:"level" env:"SLACK_MCP_LOG_LEVEL" reload:"live"`
+	Format string `yaml:"format" toml:"format" env:"SLACK_MCP_LOG_FORMAT"`
+	// Color is "true" or "false", empty means auto-detect.
+	Color string `yaml:"color" toml:"color" env:"SLACK_MCP_LOG_COLOR"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Host:            "127.0.0.1",
+			Port:            13080,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		OAuth: OAuth{
+			ReadScope:  "slack:read",
+			WriteScope: "slack:write",
+		},
+		AddMessage: AddMessage{
+			Broadcast: "text",
+		},
+		Images: Images{
+			MaxDimension: 1568,
+		},
+		SecretScan: SecretScan{
+			Action: "block",
+		},
+		Confirm: Confirm{
+			Action:   "skip",
+			Fallback: "refuse",
+		},
+		Log: Log{
+			Level: "info",
+		},
+	}
+}
+
+// Load reads the config file at path, which may be empty, applies environment overrides
+// and validates the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// FromEnv builds a configuration from defaults and environment variables only,
+// ignoring invalid values.
+func FromEnv() *Config {
+	cfg := Default()
+	_ = applyEnv(cfg)
+	return cfg
+}
+
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown key %q in config file %s", undecoded[0].String(), path)
+		}
+	default:
+		return fmt.Errorf("config file %s must have a .yaml, .yml or .toml extension", path)
+	}
+	return nil
+}
+
+// field is a leaf setting with its dotted file path, e.g. "add_message.tool".
+type field struct {
+	path  string
+	env   string
+	live  bool
+	value reflect.Value
+}
+
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(v.Field(i), name)
+				continue
+			}
+			out = append(out, field{
+				path:  name,
+				env:   sf.Tag.Get("env"),
+				live:  sf.Tag.Get("reload") == "live",
+				value: v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// envAliases maps environment variables to the deprecated names they replaced, which
+// are still read when the new name is not set.
+var envAliases = map[string]string{
+	"SLACK_MCP_API_KEY": "SLACK_MCP_SSE_API_KEY",
+}
+
+// legacyBoolEnv lists the boolean variables that used to be enabled by any non-empty
+// value. Only an explicit false disables them, so existing setups keep working.
+var legacyBoolEnv = map[string]bool{
+	"SLACK_MCP_CUSTOM_TLS":         true,
+	"SLACK_MCP_SERVER_CA_TOOLKIT":  true,
+	"SLACK_MCP_SERVER_CA_INSECURE": true,
+}
+
+// applyEnv overrides cfg with every environment variable that is set. All invalid
+// values are reported, not just the first.
+func applyEnv(cfg *Config) error {
+	var errs []error
+	for _, f := range fields(cfg) {
+		name := f.env
+		raw := os.Getenv(name)
+		if raw == "" && envAliases[f.env] != "" {
+			name = envAliases[f.env]
+			raw = os.Getenv(name)
+		}
+		if raw == "" {
+			continue
+		}
+		if legacyBoolEnv[name] {
+			b, err := parseBool(raw)
+			f.value.SetBool(b || err != nil)
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, raw, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DeprecatedEnv describes the deprecated environment variables in use, for a warning
+// at startup.
+func DeprecatedEnv() []string {
+	var msgs []string
+	for name, old := range envAliases {
+		if os.Getenv(name) == "" && os.Getenv(old) != "" {
+			msgs = append(msgs, fmt.Sprintf("%s is deprecated, please use %s", old, name))
+		}
+	}
+	slices.Sort(msgs)
+	return msgs
+}
+
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// parseBool accepts the spellings used across the SLACK_MCP_* variables.
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, errors.New("must be true or false")
+}
+
+// Validate checks settings that would otherwise only fail when first used.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if err := ValidateToolPolicy(c.AddMessage.Tool); err != nil {
+		errs = append(errs, fmt.Errorf("add_message.tool: %w", err))
+	}
+	switch c.AddMessage.Broadcast {
+	case "text", "allow", "deny":
+	default:
+		errs = append(errs, fmt.Errorf("add_message.broadcast: %q must be text, allow or deny", c.AddMessage.Broadcast))
+	}
+	if err := ValidateToolPolicy(c.FilesUpload.Tool); err != nil {
+		errs = append(errs, fmt.Errorf("files_upload.tool: %w", err))
+	}
+	if c.FilesUpload.Dir != "" && !filepath.IsAbs(c.FilesUpload.Dir) {
+		errs = append(errs, fmt.Errorf("files_upload.dir: %q must be an absolute path", c.FilesUpload.Dir))
+	}
+	if c.Images.MaxDimension < 64 || c.Images.MaxDimension > 8192 {
+		errs = append(errs, fmt.Errorf("images.max_dimension: %d must be between 64 and 8192", c.Images.MaxDimension))
+	}
+	if len(c.Tools.Enabled) > 0 && len(c.Tools.Disabled) > 0 {
+		errs = append(errs, errors.New("tools: enabled and disabled cannot be used together"))
+	}
+	for _, entry := range append(slices.Clone(c.ReadPolicy.Allow), c.ReadPolicy.Deny...) {
+		if err := validateReadEntry(entry); err != nil {
+			errs = append(errs, fmt.Errorf("read_policy: %w", err))
+		}
+	}
+	if err := validateScanAction(c.SecretScan.Action); err != nil {
+		errs = append(errs, fmt.Errorf("secret_scan.action: %w", err))
+	}
+	for _, rule := range c.SecretScan.Channels {
+		if err := validateChannelRule(rule, validateScanAction); err != nil {
+			errs = append(errs, fmt.Errorf("secret_scan.channels: %w", err))
+		}
+	}
+	for _, pattern := range c.SecretScan.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("secret_scan.patterns: %w", err))
+		}
+	}
+	for _, entry := range c.Redaction.Channels {
+		if err := validateReadEntry(entry); err != nil {
+			errs = append(errs, fmt.Errorf("redaction.channels: %w", err))
+		}
+	}
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("redaction.patterns: %w", err))
+		}
+	}
+	if err := validateConfirmAction(c.Confirm.Action); err != nil {
+		errs = append(errs, fmt.Errorf("confirm.action: %w", err))
+	}
+	for _, rule := range c.Confirm.Channels {
+		if err := validateChannelRule(rule, validateConfirmAction); err != nil {
+			errs = append(errs, fmt.Errorf("confirm.channels: %w", err))
+		}
+	}
+	switch c.Confirm.Fallback {
+	case "refuse", "post":
+	default:
+		errs = append(errs, fmt.Errorf("confirm.fallback: %q must be refuse or post", c.Confirm.Fallback))
+	}
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: %d is out of range", c.Server.Port))
+	}
+	if c.Server.MetricsPort < 0 || c.Server.MetricsPort > 65535 {
+		errs = append(errs, fmt.Errorf("server.metrics_port: %d is out of range", c.Server.MetricsPort))
+	}
+	if c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must not be negative"))
+	}
+	if (c.Server.TLS.Cert == "") != (c.Server.TLS.Key == "") {
+		errs = append(errs, errors.New("server.tls: both cert and key must be set to enable TLS"))
+	}
+	if c.Approval.ReviewerKey != "" && c.Approval.ReviewerKey == c.Server.APIKey {
+		errs = append(errs, errors.New("approval.reviewer_key must differ from server.api_key"))
+	}
+	if c.Server.TLS.ClientCA != "" && c.Server.TLS.Cert == "" {
+		errs = append(errs, errors.New("server.tls.client_ca requires cert and key"))
+	}
+	if c.Network.Proxy != "" {
+		if c.Network.CustomTLS {
+			errs = append(errs, errors.New("network.proxy and network.custom_tls cannot be used together"))
+		}
+		if _, err := url.Parse(c.Network.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("network.proxy: %w", err))
+		}
+	}
+	if c.Network.ServerCA != "" && c.Network.ServerCAInsecure {
+		errs = append(errs, errors.New("network.server_ca and network.server_ca_insecure cannot be used together"))
+	}
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "", "json", "console":
+	default:
+		errs = append(errs, fmt.Errorf("log.format: %q must be json or console", c.Log.Format))
+	}
+	switch strings.ToLower(c.Log.Color) {
+	case "", "true", "1", "false", "0":
+	default:
+		errs = append(errs, fmt.Errorf("log.color: %q must be true or false", c.Log.Color))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateReadEntry(entry string) error {
+	if kind, ok := strings.CutPrefix(entry, "type:"); ok {
+		switch kind {
+		case "im", "mpim", "private", "public":
+			return nil
+		}
+		return fmt.Errorf("unknown conversation type %q, must be im, mpim, private or public", kind)
+	}
+	if _, err := path.Match(entry, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", entry, err)
+	}
+	return nil
+}
+
+func validateScanAction(action string) error {
+	switch action {
+	case "block", "redact", "allow":
+		return nil
+	}
+	return fmt.Errorf("%q must be block, redact or allow", action)
+}
+
+func validateConfirmAction(action string) error {
+	switch action {
+	case "confirm", "skip":
+		return nil
+	}
+	return fmt.Errorf("%q must be confirm or skip", action)
+}
+
+func validateChannelRule(rule string, validateAction func(string) error) error {
+	entry, action, ok := strings.Cut(rule, "=")
+	if !ok {
+		return fmt.Errorf("rule %q must have the form <channel>=<action>", rule)
+	}
+	if err := validateReadEntry(strings.TrimSpace(entry)); err != nil {
+		return err
+	}
+	return validateAction(strings.TrimSpace(action))
+}
+
+// ValidateToolPolicy checks a conversations_add_message channel policy: allowed and
+// "!"-prefixed disallowed channels cannot be mixed.
+func ValidateToolPolicy(policy string) error {
+	if policy == "" || policy == "true" || policy == "1" {
+		return nil
+	}
+
+	hasNegated := false
+	hasPositive := false
+	for _, item := range strings.Split(policy, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.HasPrefix(item, "!") {
+			hasNegated = true
+		} else {
+			hasPositive = true
+		}
+	}
+
+	if hasNegated && hasPositive {
+		return fmt.Errorf("cannot mix allowed and disallowed (! prefixed) channels")
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestUnitLoad(t *testing.T) {
+	t.Run("yaml with env override", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  shutdown_timeout: 5s
+add_message:
+  tool: C123,C456
+  mark: true
+team:
+  name: Platform
+  priority_channels: ["#general", "C789"]
+`)
+		t.Setenv("SLACK_MCP_PORT", "9100")
+		t.Setenv("SLACK_MCP_PRIORITY_USERS", "@alice, @bob")
+
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, 9100, cfg.Server.Port)
+		assert.Equal(t, "127.0.0.1", cfg.Server.Host)
+		assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, "C123,C456", cfg.AddMessage.Tool)
+		assert.True(t, cfg.AddMessage.Mark)
+		assert.Equal(t, []string{"#general", "C789"}, cfg.Team.PriorityChannels)
+		assert.Equal(t, []string{"@alice", "@bob"}, cfg.Team.PriorityUsers)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+[network]
+proxy = "http://proxy:3128"
+
+[log]
+level = "debug"
+`)
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, "http://proxy:3128", cfg.Network.Proxy)
+		assert.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("env only", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_XOXP_TOKEN", "demo")
+		t.Setenv("SLACK_MCP_ADD_MESSAGE_MARK", "yes")
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.True(t, cfg.Slack.IsDemo())
+		assert.True(t, cfg.AddMessage.Mark)
+		assert.Equal(t, 13080, cfg.Server.Port)
+	})
+
+	t.Run("legacy env", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_CUSTOM_TLS", "enabled")
+		t.Setenv("SLACK_MCP_SERVER_CA_TOOLKIT", "y")
+		t.Setenv("SLACK_MCP_SERVER_CA_INSECURE", "false")
+		t.Setenv("SLACK_MCP_API_KEY", "")
+		t.Setenv("SLACK_MCP_SSE_API_KEY", "old-key")
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.True(t, cfg.Network.CustomTLS)
+		assert.True(t, cfg.Network.ServerCAToolkit)
+		assert.False(t, cfg.Network.ServerCAInsecure)
+		assert.Equal(t, "old-key", cfg.Server.APIKey)
+		assert.Equal(t, []string{"SLACK_MCP_SSE_API_KEY is deprecated, please use SLACK_MCP_API_KEY"}, DeprecatedEnv())
+
+		t.Setenv("SLACK_MCP_API_KEY", "new-key")
+		cfg, err = Load("")
+		require.NoError(t, err)
+		assert.Equal(t, "new-key", cfg.Server.APIKey)
+		assert.Empty(t, DeprecatedEnv())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			file    string
+			content string
+			env     map[string]string
+			want    string
+		}{
+			{"unknown yaml key", "c.yaml", "server:\n  prot: 1\n", nil, "prot"},
+			{"unknown toml key", "c.toml", "[server]\nprot = 1\n", nil, "server.prot"},
+			{"extension", "c.json", "{}", nil, "extension"},
+			{"mixed policy", "c.yaml", "add_message:\n  tool: C1,!C2\n", nil, "cannot mix"},
+			{"broadcast policy", "c.yaml", "add_message:\n  broadcast: quiet\n", nil, "add_message.broadcast"},
+			{"upload policy", "c.yaml", "files_upload:\n  tool: C1,!C2\n", nil, "files_upload.tool"},
+			{"relative upload dir", "c.yaml", "files_upload:\n  dir: reports\n", nil, "files_upload.dir"},
+			{"image dimension", "c.yaml", "", map[string]string{"SLACK_MCP_IMAGE_MAX_DIMENSION": "16"}, "images.max_dimension"},
+			{"bad env bool", "c.yaml", "", map[string]string{"SLACK_MCP_READ_ONLY": "maybe"}, "SLACK_MCP_READ_ONLY"},
+			{"bad env int", "c.yaml", "", map[string]string{"SLACK_MCP_PORT": "http"}, "SLACK_MCP_PORT"},
+			{"proxy and custom tls", "c.yaml", "network:\n  proxy: http://p\n  custom_tls: true\n", nil, "cannot be used together"},
+			{"tls key missing", "c.yaml", "server:\n  tls:\n    cert: cert.pem\n", nil, "both cert and key"},
+			{"reviewer key reuses api key", "c.yaml", "server:\n  api_key: k\napproval:\n  reviewer_key: k\n", nil, "approval.reviewer_key"},
+			{"log level", "c.yaml", "log:\n  level: loud\n", nil, "log.level"},
+			{"read policy type", "c.yaml", "read_policy:\n  deny: [\"type:secret\"]\n", nil, "read_policy"},
+			{"read policy pattern", "c.yaml", "read_policy:\n  deny: [\"#hr-[\"]\n", nil, "invalid pattern"},
+			{"secret scan action", "c.yaml", "secret_scan:\n  action: warn\n", nil, "secret_scan.action"},
+			{"secret scan rule", "c.yaml", "secret_scan:\n  channels: [\"#general\"]\n", nil, "<channel>=<action>"},
+			{"secret scan pattern", "c.yaml", "secret_scan:\n  patterns: [\"EMP-(\"]\n", nil, "secret_scan.patterns"},
+			{"redaction pattern", "c.yaml", "redaction:\n  patterns: [\"CASE-[\"]\n", nil, "redaction.patterns"},
+			{"redaction channel", "c.yaml", "redaction:\n  channels: [\"type:support\"]\n", nil, "redaction.channels"},
+			{"confirm rule action", "c.yaml", "confirm:\n  channels: [\"type:public=block\"]\n", nil, "confirm.channels"},
+			{"confirm fallback", "c.yaml", "", map[string]string{"SLACK_MCP_CONFIRM_FALLBACK": "ask"}, "confirm.fallback"},
+			{"enabled and disabled tools", "c.yaml", "tools:\n  enabled: [channels_list]\n  disabled: [get_image]\n", nil, "tools"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				for k, v := range tt.env {
+					t.Setenv(k, v)
+				}
+				_, err := Load(writeFile(t, tt.file, tt.content))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+}
+
+func TestUnitValidateToolPolicy(t *testing.T) {
+	assert.NoError(t, ValidateToolPolicy(""))
+	assert.NoError(t, ValidateToolPolicy("true"))
+	assert.NoError(t, ValidateToolPolicy("C1, C2"))
+	assert.NoError(t, ValidateToolPolicy("!C1,!C2"))
+	assert.Error(t, ValidateToolPolicy("C1,!C2"))
+}
+
+func TestUnitDiff(t *testing.T) {
+	old := Default()
+	updated := Default()
+	updated.AddMessage.Tool = "true"
+	updated.Team.PriorityChannels = []string{"#general"}
+	updated.Server.Port = 9000
+
+	live, restart := Diff(old, updated)
+	assert.Equal(t, []string{"add_message.tool", "team.priority_channels"}, live)
+	assert.Equal(t, []string{"server.port"}, restart)
+}
+
+func TestUnitWatcher(t *testing.T) {
+	t.Cleanup(func() { Set(nil) })
+
+	path := writeFile(t, "config.yaml", "add_message:\n  tool: C1\n")
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	Set(cfg)
+
+	var reloads int
+	w := NewWatcher(path, zap.NewNop(), func(old, updated *Config) { reloads++ })
+
+	w.check()
+	assert.Equal(t, 0, reloads, "unchanged file must not reload")
+
+	require.NoError(t, os.WriteFile(path, []byte("add_message:\n  tool: C1,C2\n"), 0o600))
+	w.check()
+	assert.Equal(t, 1, reloads)
+	assert.Equal(t, "C1,C2", Current().AddMessage.Tool)
+
+	// An invalid edit keeps the previous configuration.
+	require.NoError(t, os.WriteFile(path, []byte("add_message:\n  tool: C1,!C2\n"), 0o600))
+	w.check()
+	assert.Equal(t, 1, reloads)
+	assert.Equal(t, "C1,C2", Current().AddMessage.Tool)
+
+	// Settings that need a restart keep their value.
+	require.NoError(t, os.WriteFile(path, []byte("add_message:\n  tool: C3\noauth:\n  issuer: https://idp.example.com\nserver:\n  port: 9000\n"), 0o600))
+	w.check()
+	assert.Equal(t, 2, reloads)
+	assert.Equal(t, "C3", Current().AddMessage.Tool)
+	assert.Empty(t, Current().OAuth.Issuer)
+	assert.Equal(t, cfg.Server.Port, Current().Server.Port)
+}
+
+func TestUnitCurrentFallsBackToEnv(t *testing.T) {
+	Set(nil)
+	t.Setenv("SLACK_MCP_TEAM_NAME", "Infra")
+	assert.Equal(t, "Infra", Current().Team.Name)
+}
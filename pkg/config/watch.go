@@ -0,0 +1,147 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reloadInterval bounds how often the config file is checked for changes.
+const reloadInterval = 2 * time.Second
+
+var current atomic.Pointer[Config]
+
+// Current returns the active configuration. Before Set is called it is built from the
+// environment on every call, which keeps tests that use t.Setenv working.
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	return FromEnv()
+}
+
+// Set makes cfg the active configuration. Passing nil reverts to the environment.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Watcher reloads the config file when it changes.
+type Watcher struct {
+	path     string
+	logger   *zap.Logger
+	onReload func(old, updated *Config)
+
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher watches path; onReload, which may be nil, is called after the live
+// settings of a changed file were made current.
+func NewWatcher(path string, logger *zap.Logger, onReload func(old, updated *Config)) *Watcher {
+	w := &Watcher{path: path, logger: logger, onReload: onReload}
+	if fi, err := os.Stat(path); err == nil {
+		w.modTime, w.size = fi.ModTime(), fi.Size()
+	}
+	return w
+}
+
+// Run polls the file until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check reloads the file if it changed. An invalid file keeps the previous config, so a
+// half-written edit never takes the server down.
+func (w *Watcher) check() {
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		w.logger.Warn("Failed to stat config file", zap.String("path", w.path), zap.Error(err))
+		return
+	}
+	if fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return
+	}
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+
+	updated, err := Load(w.path)
+	if err != nil {
+		w.logger.Error("Failed to reload config file, keeping previous config",
+			zap.String("context", "console"),
+			zap.String("path", w.path),
+			zap.Error(err),
+		)
+		return
+	}
+
+	old := Current()
+	live, restart := Diff(old, updated)
+	updated = applyLive(old, updated)
+	if err := updated.Validate(); err != nil {
+		w.logger.Error("Reloaded settings conflict with settings that need a restart, keeping previous config",
+			zap.String("context", "console"),
+			zap.String("path", w.path),
+			zap.Error(err),
+		)
+		return
+	}
+	Set(updated)
+
+	w.logger.Info("Reloaded config file",
+		zap.String("context", "console"),
+		zap.String("path", w.path),
+		zap.Strings("applied", live),
+	)
+	if len(restart) > 0 {
+		w.logger.Warn("Some changed settings only take effect after a restart",
+			zap.String("context", "console"),
+			zap.Strings("settings", restart),
+		)
+	}
+	if w.onReload != nil {
+		w.onReload(old, updated)
+	}
+}
+
+// applyLive returns a copy of old with the settings tagged reload:"live" taken from
+// updated. The others keep their value until the server restarts.
+func applyLive(old, updated *Config) *Config {
+	merged := *old
+	src := fields(updated)
+	for i, f := range fields(&merged) {
+		if f.live {
+			f.value.Set(src[i].value)
+		}
+	}
+	return &merged
+}
+
+// Diff lists the settings that differ between old and updated, split into those applied
+// on reload and those that need a restart.
+func Diff(old, updated *Config) (live, restart []string) {
+	a, b := fields(old), fields(updated)
+	for i := range a {
+		if reflect.DeepEqual(a[i].value.Interface(), b[i].value.Interface()) {
+			continue
+		}
+		if a[i].live {
+			live = append(live, a[i].path)
+		} else {
+			restart = append(restart, a[i].path)
+		}
+	}
+	return live, restart
+}
@@ -0,0 +1,176 @@
+// Package extract turns the content of files shared in Slack into plain text: text
+// files and snippets as they are, PDFs page by page and Office Open XML documents
+// (docx, xlsx, pptx) paragraph, row or slide by slide. Only pure-Go parsers are used.
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Kind is how the text of a file is extracted.
+type Kind string
+
+const (
+	KindText   Kind = "text"
+	KindPDF    Kind = "pdf"
+	KindDOCX   Kind = "docx"
+	KindXLSX   Kind = "xlsx"
+	KindPPTX   Kind = "pptx"
+	KindBinary Kind = "binary"
+)
+
+// ErrNoText is returned for content that has no extractable text, such as binary files
+// or scanned PDFs.
+var ErrNoText = errors.New("the file contains no extractable text")
+
+// Result is the text extracted from a file.
+type Result struct {
+	Kind Kind
+	Text string
+	// Truncated is set when the text was cut at the budget.
+	Truncated bool
+}
+
+// textFiletypes are Slack filetypes of plain text, code and data files.
+var textFiletypes = map[string]bool{
+	"text": true, "csv": true, "tsv": true, "markdown": true, "post": true, "json": true,
+	"xml": true, "yaml": true, "html": true, "css": true, "diff": true, "shell": true,
+	"sql": true, "javascript": true, "typescript": true, "python": true, "go": true,
+	"java": true, "kotlin": true, "c": true, "cpp": true, "csharp": true, "rust": true,
+	"ruby": true, "php": true, "swift": true, "scala": true, "perl": true, "lua": true,
+	"powershell": true, "dockerfile": true, "toml": true, "ini": true, "log": true,
+}
+
+// textMimetypes are non text/* MIME types that hold text.
+var textMimetypes = map[string]bool{
+	"application/json": true, "application/xml": true, "application/x-yaml": true,
+	"application/yaml": true, "application/javascript": true, "application/x-sh": true,
+	"application/sql": true, "application/toml": true, "application/x-ndjson": true,
+}
+
+// Classify returns how text is extracted from a file with the given Slack filetype,
+// MIME type and name.
+func Classify(filetype, mimetype, name string) Kind {
+	mimetype = strings.ToLower(strings.TrimSpace(strings.Split(mimetype, ";")[0]))
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+	filetype = strings.ToLower(filetype)
+
+	switch {
+	case filetype == "pdf" || mimetype == "application/pdf":
+		return KindPDF
+	case filetype == "docx" || mimetype == "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return KindDOCX
+	case filetype == "xlsx" || mimetype == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return KindXLSX
+	case filetype == "pptx" || mimetype == "application/vnd.openxmlformats-officedocument.presentationml.presentation":
+		return KindPPTX
+	case textFiletypes[filetype] || textFiletypes[ext] || strings.HasPrefix(mimetype, "text/") || textMimetypes[mimetype]:
+		return KindText
+	}
+	return KindBinary
+}
+
+// Text extracts at most budget bytes of text from data, which is of the given kind.
+func Text(kind Kind, data []byte, budget int) (Result, error) {
+	w := &budgetWriter{budget: budget}
+	var err error
+	switch kind {
+	case KindText:
+		if !utf8.Valid(data) {
+			return Result{Kind: kind}, ErrNoText
+		}
+		err = w.WriteString(string(data))
+	case KindPDF:
+		err = pdfText(data, w)
+	case KindDOCX:
+		err = docxText(data, w)
+	case KindXLSX:
+		err = xlsxText(data, w)
+	case KindPPTX:
+		err = pptxText(data, w)
+	default:
+		return Result{Kind: kind}, ErrNoText
+	}
+	if err != nil && !errors.Is(err, errBudget) {
+		return Result{Kind: kind}, err
+	}
+
+	text := strings.TrimSpace(w.buf.String())
+	if text == "" {
+		return Result{Kind: kind}, ErrNoText
+	}
+	return Result{Kind: kind, Text: text, Truncated: w.truncated}, nil
+}
+
+// errBudget stops extraction once the budget is used up.
+var errBudget = errors.New("text budget exhausted")
+
+// budgetWriter collects text up to budget bytes, cutting at a rune boundary.
+type budgetWriter struct {
+	buf       bytes.Buffer
+	budget    int
+	truncated bool
+}
+
+func (w *budgetWriter) WriteString(s string) error {
+	if w.truncated {
+		return errBudget
+	}
+	if left := w.budget - w.buf.Len(); len(s) > left {
+		for left > 0 && !utf8.RuneStart(s[left]) {
+			left--
+		}
+		w.buf.WriteString(s[:left])
+		w.truncated = true
+		return errBudget
+	}
+	w.buf.WriteString(s)
+	return nil
+}
+
+func pdfText(data []byte, w *budgetWriter) (err error) {
+	// The PDF parser panics on some malformed files.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= r.NumPage(); i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		for _, name := range p.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				f := p.Font(name)
+				fonts[name] = &f
+			}
+		}
+		text, err := p.GetPlainText(fonts)
+		if err != nil {
+			return fmt.Errorf("failed to read PDF page %d: %w", i, err)
+		}
+		if i > 1 {
+			if err := w.WriteString(fmt.Sprintf("\n\n--- page %d ---\n\n", i)); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteString(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestUnitClassify(t *testing.T) {
+	tests := []struct {
+		filetype, mimetype, name string
+		want                     Kind
+	}{
+		{"pdf", "application/pdf", "a.pdf", KindPDF},
+		{"docx", "", "a.docx", KindDOCX},
+		{"", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "a", KindXLSX},
+		{"pptx", "", "deck.pptx", KindPPTX},
+		{"text", "text/plain", "notes.txt", KindText},
+		{"python", "text/x-python", "main.py", KindText},
+		{"", "application/json; charset=utf-8", "data", KindText},
+		{"binary", "application/octet-stream", "build.log", KindText},
+		{"zip", "application/zip", "a.zip", KindBinary},
+		{"mp4", "video/mp4", "a.mp4", KindBinary},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Classify(tt.filetype, tt.mimetype, tt.name), tt.name)
+	}
+}
+
+func TestUnitTextPlain(t *testing.T) {
+	res, err := Text(KindText, []byte("hello\nworld\n"), 100)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\nworld", res.Text)
+	assert.False(t, res.Truncated)
+
+	res, err = Text(KindText, []byte("héllo world"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "h", res.Text)
+	assert.True(t, res.Truncated)
+
+	_, err = Text(KindText, []byte{0xff, 0xfe, 0x00}, 100)
+	assert.ErrorIs(t, err, ErrNoText)
+
+	_, err = Text(KindBinary, []byte("x"), 100)
+	assert.ErrorIs(t, err, ErrNoText)
+}
+
+func TestUnitTextDOCX(t *testing.T) {
+	data := zipOf(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			`<w:p><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t xml:space="preserve"> report</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:t>a</w:t><w:tab/><w:t>b</w:t><w:br/><w:t>c</w:t></w:r></w:p>` +
+			`</w:body></w:document>`,
+	})
+	res, err := Text(KindDOCX, data, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly report\na\tb\nc", res.Text)
+
+	_, err = Text(KindDOCX, zipOf(t, map[string]string{"other.xml": "<x/>"}), 1000)
+	assert.ErrorContains(t, err, "word/document.xml is missing")
+
+	_, err = Text(KindDOCX, []byte("not a zip"), 1000)
+	assert.ErrorContains(t, err, "failed to open document")
+}
+
+func TestUnitTextXLSX(t *testing.T) {
+	data := zipOf(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+			`<sheet name="Budget" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Item</t></si><si><t>Cost</t></si><si><r><t>Lap</t></r><r><t>top, new</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>1200</v></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>Total</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+	res, err := Text(KindXLSX, data, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "## Sheet: Budget\nItem,Cost\n\"Laptop, new\",,1200\nTotal", res.Text)
+}
+
+func TestUnitTextPPTX(t *testing.T) {
+	slide := func(text string) string {
+		return `<p:sld xmlns:p="p" xmlns:a="a"><p:cSld><p:spTree><p:sp><p:txBody><a:p><a:r><a:t>` +
+			text + `</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+	}
+	data := zipOf(t, map[string]string{
+		"ppt/slides/slide10.xml": slide("Questions"),
+		"ppt/slides/slide2.xml":  slide("Roadmap"),
+		"ppt/slides/slide1.xml":  slide("Welcome"),
+	})
+	res, err := Text(KindPPTX, data, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "## Slide 1\nWelcome\n\n## Slide 2\nRoadmap\n\n## Slide 10\nQuestions", res.Text)
+
+	res, err = Text(KindPPTX, data, 20)
+	require.NoError(t, err)
+	assert.Equal(t, "## Slide 1\nWelcome", res.Text)
+	assert.True(t, res.Truncated)
+}
+
+func TestUnitTextPDFInvalid(t *testing.T) {
+	_, err := Text(KindPDF, []byte("%PDF-1.4 garbage"), 1000)
+	assert.ErrorContains(t, err, "failed to parse PDF")
+}
@@ -0,0 +1,328 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize caps how much of a single zip entry is decompressed, so a zip bomb
+// cannot exhaust memory.
+const maxPartSize = 64 * 1024 * 1024
+
+// openPart returns the decompressed content of the named zip entry, or nil when the
+// archive has no such entry.
+func openPart(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if len(data) > maxPartSize {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+func openZip(data []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	return zr, nil
+}
+
+// docxText writes the paragraphs of word/document.xml, one per line.
+func docxText(data []byte, w *budgetWriter) error {
+	zr, err := openZip(data)
+	if err != nil {
+		return err
+	}
+	doc, err := openPart(zr, "word/document.xml")
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return errors.New("failed to open document: word/document.xml is missing")
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	inText := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse document: %w", err)
+		}
+		var out string
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				out = "\t"
+			case "br", "cr":
+				out = "\n"
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				out = "\n"
+			}
+		case xml.CharData:
+			if inText {
+				out = string(t)
+			}
+		}
+		if out != "" {
+			if err := w.WriteString(out); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// pptxText writes the text of each slide under a "## Slide N" heading.
+func pptxText(data []byte, w *budgetWriter) error {
+	zr, err := openZip(data)
+	if err != nil {
+		return err
+	}
+
+	type slide struct {
+		n    int
+		name string
+	}
+	var slides []slide
+	for _, f := range zr.File {
+		rest, ok := strings.CutPrefix(f.Name, "ppt/slides/slide")
+		if !ok || !strings.HasSuffix(rest, ".xml") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(rest, ".xml")); err == nil {
+			slides = append(slides, slide{n, f.Name})
+		}
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].n < slides[j].n })
+
+	for _, s := range slides {
+		part, err := openPart(zr, s.name)
+		if err != nil {
+			return err
+		}
+		if err := w.WriteString(fmt.Sprintf("## Slide %d\n", s.n)); err != nil {
+			return err
+		}
+		d := xml.NewDecoder(bytes.NewReader(part))
+		inText := false
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse slide %d: %w", s.n, err)
+			}
+			var out string
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "t" {
+					inText = true
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "t":
+					inText = false
+				case "p":
+					out = "\n"
+				}
+			case xml.CharData:
+				if inText {
+					out = string(t)
+				}
+			}
+			if out != "" {
+				if err := w.WriteString(out); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRels struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				T string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// xlsxText writes each worksheet as CSV under a "## Sheet: name" heading.
+func xlsxText(data []byte, w *budgetWriter) error {
+	zr, err := openZip(data)
+	if err != nil {
+		return err
+	}
+
+	var shared []string
+	if part, err := openPart(zr, "xl/sharedStrings.xml"); err != nil {
+		return err
+	} else if part != nil {
+		var sst xlsxSharedStrings
+		if err := xml.Unmarshal(part, &sst); err != nil {
+			return fmt.Errorf("failed to parse shared strings: %w", err)
+		}
+		for _, si := range sst.Items {
+			s := si.T
+			for _, r := range si.Runs {
+				s += r.T
+			}
+			shared = append(shared, s)
+		}
+	}
+
+	var wb xlsxWorkbook
+	var rels xlsxRels
+	for name, v := range map[string]any{"xl/workbook.xml": &wb, "xl/_rels/workbook.xml.rels": &rels} {
+		part, err := openPart(zr, name)
+		if err != nil {
+			return err
+		}
+		if part == nil {
+			return fmt.Errorf("failed to open workbook: %s is missing", name)
+		}
+		if err := xml.Unmarshal(part, v); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, r := range rels.Relationships {
+		target := strings.TrimPrefix(r.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[r.ID] = target
+	}
+
+	for _, s := range wb.Sheets {
+		part, err := openPart(zr, targets[s.RID])
+		if err != nil {
+			return err
+		}
+		if part == nil {
+			continue
+		}
+		var sheet xlsxSheet
+		if err := xml.Unmarshal(part, &sheet); err != nil {
+			return fmt.Errorf("failed to parse sheet %q: %w", s.Name, err)
+		}
+
+		var buf bytes.Buffer
+		cw := csv.NewWriter(&buf)
+		for _, row := range sheet.Rows {
+			var record []string
+			for _, c := range row.Cells {
+				if col := columnIndex(c.Ref); col >= 0 {
+					for len(record) < col {
+						record = append(record, "")
+					}
+				}
+				value := c.Value
+				switch c.Type {
+				case "s":
+					if i, err := strconv.Atoi(c.Value); err == nil && i >= 0 && i < len(shared) {
+						value = shared[i]
+					}
+				case "inlineStr":
+					value = c.Inline.T
+				}
+				record = append(record, value)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+
+		if err := w.WriteString(fmt.Sprintf("## Sheet: %s\n", s.Name)); err != nil {
+			return err
+		}
+		if err := w.WriteString(buf.String() + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based column of a cell reference like "C7", or -1.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
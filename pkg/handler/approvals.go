@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+const maxListedActions = 50
+
+// PendingAction is an approval queue entry as reported to agents.
+type PendingAction struct {
+	ID        string `json:"id"`
+	Tool      string `json:"tool"`
+	Status    string `json:"status"`
+	Channel   string `json:"channelID"`
+	Text      string `json:"text"`
+	Edited    bool   `json:"edited"`
+	Reason    string `json:"reason"`
+	TS        string `json:"ts"`
+	Permalink string `json:"permalink"`
+	Error     string `json:"error"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// enqueueMessage stores the rendered message in the approval queue instead of posting it.
+func (ch *ConversationsHandler) enqueueMessage(ctx context.Context, params *addMessageParams) (*mcp.CallToolResult, error) {
+	_, blocks, err := ch.messageOptions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &approval.Action{
+		Tool:    "conversations_add_message",
+		Channel: params.channel,
+		Text:    params.text,
+		Params:  map[string]string{"content_type": params.contentType},
+		Caller:  auth.Caller(ctx, ch.apiProvider.ServerTransport()),
+	}
+	if params.threadTs != "" {
+		action.Params["thread_ts"] = params.threadTs
+	}
+	if len(blocks) > 0 {
+		if preview, err := json.Marshal(map[string]any{"blocks": blocks}); err == nil {
+			action.Preview = preview
+		}
+	}
+	if err := ch.approvals.Enqueue(action); err != nil {
+		ch.logger.Error("Failed to queue message for approval", zap.Error(err))
+		return nil, err
+	}
+
+	ch.logger.Info("Message queued for approval",
+		zap.String("id", action.ID),
+		zap.String("channel", action.Channel),
+		zap.String("caller", action.Caller),
+	)
+	result := mcp.NewToolResultText(fmt.Sprintf(
+		"Message queued for human approval with id %s; it has NOT been posted yet. Call pending_actions_status with id %s to learn whether it was approved, edited, rejected or posted.",
+		action.ID, action.ID))
+	result.StructuredContent = map[string]any{"id": action.ID, "status": action.Status}
+	return result, nil
+}
+
+// PostApprovedMessage runs an approved conversations_add_message action. The channel
+// policy and secret scan are applied again, since either may have changed, and a
+// reviewer may have edited the text, since the message was queued.
+func (ch *ConversationsHandler) PostApprovedMessage(ctx context.Context, a *approval.Action) (ts, permalink string, err error) {
+	if config.Current().AddMessage.Tool == "" || !isChannelAllowed(a.Channel) {
+		return "", "", fmt.Errorf("conversations_add_message is no longer allowed for channel %q", a.Channel)
+	}
+
+	params := &addMessageParams{
+		channel:     a.Channel,
+		threadTs:    a.Params["thread_ts"],
+		text:        a.Text,
+		contentType: a.Params["content_type"],
+	}
+	if params.contentType == "" {
+		params.contentType = "text/markdown"
+	}
+
+	scanned, err := scanOutgoing(ch.apiProvider, params.channel, params.text)
+	if err != nil {
+		return "", "", err
+	}
+	if scanned.blocked() {
+		return "", "", errors.New(blockedMessage(params.channel, scanned.findings))
+	}
+	params.text = scanned.text
+
+	respChannel, respTimestamp, err := ch.postMessage(ctx, params)
+	if respTimestamp == "" {
+		return "", "", err
+	}
+	// The message is posted even if marking it as read failed.
+	return respTimestamp, ch.permalink(respChannel, respTimestamp, params.threadTs), nil
+}
+
+type PendingActionsHandler struct {
+	queue  *approval.Queue
+	logger *zap.Logger
+}
+
+func NewPendingActionsHandler(queue *approval.Queue, logger *zap.Logger) *PendingActionsHandler {
+	return &PendingActionsHandler{
+		queue:  queue,
+		logger: logger,
+	}
+}
+
+// PendingActionsStatusHandler reports the state of one queued action, or of the most
+// recent ones when no id is given, as CSV.
+func (ph *PendingActionsHandler) PendingActionsStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ph.logger.Debug("PendingActionsStatusHandler called", zap.Any("params", request.Params))
+
+	var actions []*approval.Action
+	if id := request.GetString("id", ""); id != "" {
+		a, err := ph.queue.Get(id)
+		if errors.Is(err, approval.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("No queued action with id %q", id)), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	} else {
+		var statuses []approval.Status
+		if status := request.GetString("status", ""); status != "" {
+			statuses = append(statuses, approval.Status(status))
+		}
+		all, err := ph.queue.List(statuses...)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(all) - 1; i >= 0 && len(actions) < maxListedActions; i-- {
+			actions = append(actions, all[i])
+		}
+	}
+
+	rows := make([]PendingAction, 0, len(actions))
+	for _, a := range actions {
+		rows = append(rows, PendingAction{
+			ID:        a.ID,
+			Tool:      a.Tool,
+			Status:    string(a.Status),
+			Channel:   a.Channel,
+			Text:      a.Text,
+			Edited:    a.Edited,
+			Reason:    a.Reason,
+			TS:        a.TS,
+			Permalink: a.Permalink,
+			Error:     a.Error,
+			UpdatedAt: a.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
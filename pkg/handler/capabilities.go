@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// ToolCapability describes whether a tool can be used with the configured Slack token.
+type ToolCapability struct {
+	Tool      string
+	Available bool
+	// Limited means the tool is registered but some conversation types or optional
+	// features are out of reach.
+	Limited bool
+	Missing []string
+	Reason  string
+}
+
+type CapabilitiesHandler struct {
+	tools  []ToolCapability
+	scopes []string
+	logger *zap.Logger
+}
+
+// NewCapabilitiesHandler takes the tool evaluations made at registration time. A nil
+// scopes slice means the granted scopes are unknown.
+func NewCapabilitiesHandler(tools []ToolCapability, scopes []string, logger *zap.Logger) *CapabilitiesHandler {
+	return &CapabilitiesHandler{
+		tools:  tools,
+		scopes: scopes,
+		logger: logger,
+	}
+}
+
+// GetCapabilitiesHandler explains which tools are available, limited or missing for the
+// configured token and which OAuth scopes would enable them.
+func (ch *CapabilitiesHandler) GetCapabilitiesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("GetCapabilitiesHandler called", zap.Any("params", request.Params))
+
+	var parts []string
+	parts = append(parts, "# Slack MCP Server Capabilities\n")
+
+	parts = append(parts, "## Granted scopes")
+	if ch.scopes == nil {
+		parts = append(parts, "- unknown (browser or demo credentials, or auth.test did not report scopes); all tools are registered")
+	} else if len(ch.scopes) == 0 {
+		parts = append(parts, "- none")
+	} else {
+		parts = append(parts, "- "+strings.Join(ch.scopes, ", "))
+	}
+	parts = append(parts, "")
+
+	var available, limited, unavailable []string
+	for _, t := range ch.tools {
+		switch {
+		case !t.Available:
+			unavailable = append(unavailable, fmt.Sprintf("- %s: %s", t.Tool, t.Reason))
+		case t.Limited:
+			limited = append(limited, fmt.Sprintf("- %s: %s", t.Tool, t.Reason))
+		default:
+			available = append(available, "- "+t.Tool)
+		}
+	}
+
+	parts = append(parts, "## Available tools")
+	parts = append(parts, orNone(available)...)
+	parts = append(parts, "")
+	parts = append(parts, "## Limited tools")
+	parts = append(parts, orNone(limited)...)
+	parts = append(parts, "")
+	parts = append(parts, "## Unavailable tools")
+	parts = append(parts, orNone(unavailable)...)
+
+	if len(limited) > 0 || len(unavailable) > 0 {
+		parts = append(parts, "")
+		parts = append(parts, "Add the missing scopes to the Slack app, reinstall it and restart the server to enable these tools.")
+	}
+
+	return mcp.NewToolResultText(strings.Join(parts, "\n")), nil
+}
+
+func orNone(lines []string) []string {
+	if len(lines) == 0 {
+		return []string{"- none"}
+	}
+	return lines
+}
@@ -77,7 +77,11 @@ func (ch *ChannelsHandler) ChannelsResource(ctx context.Context, request mcp.Rea
 	channels := ch.apiProvider.ProvideChannelsMaps().Channels
 	ch.logger.Debug("Retrieved channels from provider", zap.Int("count", len(channels)))
 
+	policy := newReadPolicy(ch.apiProvider)
 	for _, channel := range channels {
+		if !policy.allows(channel.ID) {
+			continue
+		}
 		channelList = append(channelList, Channel{
 			ID:          channel.ID,
 			Name:        channel.Name,
@@ -107,7 +111,7 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 
 	if ready, err := ch.apiProvider.IsReady(); !ready {
 		ch.logger.Error("API provider not ready", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 
 	sortType := request.GetString("sort", "popularity")
@@ -160,6 +164,7 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	ch.logger.Debug("Total channels available", zap.Int("count", len(allChannels)))
 
 	channels := filterChannelsByTypes(allChannels, channelTypes)
+	channels = newReadPolicy(ch.apiProvider).filterChannels(channels)
 	ch.logger.Debug("Channels after filtering by type", zap.Int("count", len(channels)))
 
 	var chans []provider.Channel
@@ -203,7 +208,7 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	csvBytes, err := gocsv.MarshalBytes(&channelList)
 	if err != nil {
 		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 
 	return mcp.NewToolResultText(string(csvBytes)), nil
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/blockkit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/slack-go/slack"
+	slackGoUtil "github.com/takara2314/slack-go-util"
+	"go.uber.org/zap"
+)
+
+// confirmSchema is the form shown to the user: a single checkbox, checked by default.
+var confirmSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"post": map[string]any{
+			"type":        "boolean",
+			"title":       "Post this message",
+			"description": "Uncheck or decline to keep the message unposted.",
+			"default":     true,
+		},
+	},
+	"required": []string{"post"},
+}
+
+// confirmMessage asks the user to confirm a message when the confirm policy of its
+// channel requires it. It returns a refusal to report to the model, or "" when the
+// message may be posted.
+func (ch *ConversationsHandler) confirmMessage(ctx context.Context, params *addMessageParams) (string, error) {
+	cfg := config.Current().Confirm
+	var channels map[string]provider.Channel
+	if ch.apiProvider != nil {
+		if maps := ch.apiProvider.ProvideChannelsMaps(); maps != nil {
+			channels = maps.Channels
+		}
+	}
+	if confirmAction(cfg, channels, params.channel) != "confirm" {
+		return "", nil
+	}
+
+	session := elicitationSession(ctx)
+	if session == nil {
+		if cfg.Fallback == "post" {
+			ch.logger.Info("Client cannot confirm messages, posting without confirmation",
+				zap.String("channel", params.channel),
+			)
+			return "", nil
+		}
+		return fmt.Sprintf("Message not posted: the server requires the user to confirm messages to %s, and this client does not support confirmation prompts (MCP elicitation).",
+			params.channel), nil
+	}
+
+	result, err := session.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message:         confirmPrompt(channels, params, ch.renderedText(ctx, params)),
+			RequestedSchema: confirmSchema,
+		},
+	})
+	if err != nil {
+		ch.logger.Error("Failed to ask the user for confirmation", zap.Error(err))
+		return "", err
+	}
+
+	if result.Action == mcp.ElicitationResponseActionAccept {
+		if content, ok := result.Content.(map[string]any); !ok || content["post"] != false {
+			return "", nil
+		}
+	}
+	ch.logger.Info("User did not confirm message",
+		zap.String("channel", params.channel),
+		zap.String("action", string(result.Action)),
+	)
+	return fmt.Sprintf("Message not posted: the user did not confirm posting it to %s. Do not retry unless the user asks you to.",
+		params.channel), nil
+}
+
+// confirmAction returns the confirm action for channelID: "confirm" or "skip".
+func confirmAction(cfg config.Confirm, channels map[string]provider.Channel, channelID string) string {
+	if action, ok := channelRuleAction(cfg.Channels, channels, channelID); ok {
+		return action
+	}
+	if cfg.Action == "" {
+		return "skip"
+	}
+	return cfg.Action
+}
+
+// elicitationSession returns the client session of ctx if the client declared
+// elicitation support when it was initialized, or nil.
+func elicitationSession(ctx context.Context) server.SessionWithElicitation {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithElicitation)
+	if !ok {
+		return nil
+	}
+	if info, ok := session.(server.SessionWithClientInfo); !ok || info.GetClientCapabilities().Elicitation == nil {
+		return nil
+	}
+	return session
+}
+
+// confirmPrompt describes the message the way the user knows the channel: by name,
+// kind and whether it is a thread reply, followed by the rendered message.
+func confirmPrompt(channels map[string]provider.Channel, params *addMessageParams, rendered string) string {
+	target := params.channel
+	if channel, ok := channels[params.channel]; ok && channel.Name != "" {
+		target = fmt.Sprintf("%s (%s, %s)", channel.Name, channelKind(channel), params.channel)
+	} else if users, ok := pendingDMUsers(params.channel); ok {
+		target = "a new DM with " + strings.Join(users, ", ")
+	}
+
+	var b strings.Builder
+	if params.threadTs != "" {
+		fmt.Fprintf(&b, "Reply in thread %s of %s with this message?\n\n", params.threadTs, target)
+	} else {
+		fmt.Fprintf(&b, "Post this message to %s?\n\n", target)
+	}
+	b.WriteString(rendered)
+	return b.String()
+}
+
+// renderedText returns the message of params as Slack will show it: mentions encoded,
+// and Block Kit, attachments and markdown converted to plain text. Payloads that fail to
+// render are returned as sent.
+func (ch *ConversationsHandler) renderedText(ctx context.Context, params *addMessageParams) string {
+	msgText := ch.encodedText(ctx, params)
+	switch params.contentType {
+	case "text/markdown":
+		if blocks, err := slackGoUtil.ConvertMarkdownTextToBlocks(msgText); err == nil && len(blocks) > 0 {
+			splitRichTextMentions(blocks, encodesBroadcasts())
+			return text.BlocksToText(blocks)
+		}
+	case blockkit.BlocksContentType:
+		// The blocks are shown rather than the fallback text, which may differ from them.
+		msg, err := blockkit.ParseBlocks(msgText)
+		if err != nil {
+			break
+		}
+		var typed slack.Blocks
+		if raw, err := json.Marshal(msg.Blocks); err == nil && json.Unmarshal(raw, &typed) == nil {
+			if rendered := text.BlocksToText(typed.BlockSet); rendered != "" {
+				return rendered
+			}
+		}
+		return msg.Text
+	case blockkit.AttachmentsContentType:
+		if msg, err := blockkit.ParseAttachments(msgText); err == nil {
+			return msg.Text
+		}
+	}
+	return msgText
+}
+
+func channelKind(ch provider.Channel) string {
+	switch {
+	case ch.IsIM:
+		return "direct message"
+	case ch.IsMpIM:
+		return "group direct message"
+	case ch.IsPrivate:
+		return "private channel"
+	}
+	return "public channel"
+}
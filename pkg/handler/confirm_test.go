@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeElicitor struct {
+	response mcp.ElicitationResponse
+	asked    []string
+}
+
+func (f *fakeElicitor) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	f.asked = append(f.asked, request.Params.Message)
+	return &mcp.ElicitationResult{ElicitationResponse: f.response}, nil
+}
+
+func elicitingContext(t *testing.T, elicitor *fakeElicitor, declared bool) context.Context {
+	t.Helper()
+	session := server.NewInProcessSessionWithHandlers("test", nil, elicitor)
+	if declared {
+		session.SetClientCapabilities(mcp.ClientCapabilities{Elicitation: &struct{}{}})
+	}
+	return server.NewMCPServer("test", "0").WithContext(context.Background(), session)
+}
+
+func TestUnitConfirmAction(t *testing.T) {
+	cfg := config.Confirm{
+		Action:   "skip",
+		Channels: []string{"type:im=skip", "type:public=confirm"},
+	}
+	assert.Equal(t, "confirm", confirmAction(cfg, readPolicyChannels, "C1"))
+	assert.Equal(t, "skip", confirmAction(cfg, readPolicyChannels, "C2"))
+	assert.Equal(t, "skip", confirmAction(cfg, readPolicyChannels, "D1"))
+	assert.Equal(t, "skip", confirmAction(cfg, readPolicyChannels, "CUNKNOWN"))
+	assert.Equal(t, "skip", confirmAction(config.Confirm{}, nil, "C1"))
+}
+
+func TestUnitConfirmPrompt(t *testing.T) {
+	prompt := confirmPrompt(readPolicyChannels, &addMessageParams{channel: "C2", threadTs: "1700000000.000100"}, "Ship it")
+	assert.Equal(t, "Reply in thread 1700000000.000100 of #hr-private (private channel, C2) with this message?\n\nShip it", prompt)
+
+	prompt = confirmPrompt(nil, &addMessageParams{channel: "C9"}, "Hi")
+	assert.Equal(t, "Post this message to C9?\n\nHi", prompt)
+
+	prompt = confirmPrompt(nil, &addMessageParams{channel: "U1,U2"}, "Hi")
+	assert.Equal(t, "Post this message to a new DM with U1, U2?\n\nHi", prompt)
+}
+
+func TestUnitRenderedText(t *testing.T) {
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+	render := func(payload, contentType string) string {
+		return ch.renderedText(context.Background(), &addMessageParams{channel: "C1", text: payload, contentType: contentType})
+	}
+
+	assert.Equal(t, "plain *text* @here", render("plain *text* @here", "text/plain"))
+	assert.Equal(t, "Release\n\nShipped *v2*", render("# Release\n\nShipped **v2**", "text/markdown"))
+	assert.Equal(t, "Deploy done", render(`{"text": "fallback", "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": "Deploy done"}}]}`, "application/vnd.slack.blocks+json"))
+	assert.Equal(t, "Build failed", render(`[{"title": "Build failed", "color": "danger"}]`, "application/vnd.slack.attachments+json"))
+	assert.Equal(t, "not json", render("not json", "application/vnd.slack.blocks+json"))
+}
+
+func TestUnitConfirmMessage(t *testing.T) {
+	t.Setenv("SLACK_MCP_CONFIRM", "confirm")
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+	params := &addMessageParams{channel: "C1", text: "Hello"}
+
+	tests := []struct {
+		name     string
+		response mcp.ElicitationResponse
+		posted   bool
+	}{
+		{"accepted", mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"post": true}}, true},
+		{"unchecked", mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"post": false}}, false},
+		{"declined", mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}, false},
+		{"cancelled", mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionCancel}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elicitor := &fakeElicitor{response: tt.response}
+			refusal, err := ch.confirmMessage(elicitingContext(t, elicitor, true), params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.posted, refusal == "")
+			require.Len(t, elicitor.asked, 1)
+			assert.Contains(t, elicitor.asked[0], "Hello")
+		})
+	}
+
+	t.Run("rendered blocks", func(t *testing.T) {
+		elicitor := &fakeElicitor{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}}
+		blocks := &addMessageParams{channel: "C1", contentType: "application/vnd.slack.blocks+json",
+			text: `[{"type": "section", "text": {"type": "mrkdwn", "text": "Deploy done"}}]`}
+		_, err := ch.confirmMessage(elicitingContext(t, elicitor, true), blocks)
+		require.NoError(t, err)
+		require.Len(t, elicitor.asked, 1)
+		assert.Equal(t, "Post this message to C1?\n\nDeploy done", elicitor.asked[0])
+	})
+
+	t.Run("client without elicitation", func(t *testing.T) {
+		elicitor := &fakeElicitor{}
+		refusal, err := ch.confirmMessage(elicitingContext(t, elicitor, false), params)
+		require.NoError(t, err)
+		assert.Contains(t, refusal, "does not support confirmation")
+		assert.Empty(t, elicitor.asked)
+
+		t.Setenv("SLACK_MCP_CONFIRM_FALLBACK", "post")
+		refusal, err = ch.confirmMessage(context.Background(), params)
+		require.NoError(t, err)
+		assert.Empty(t, refusal)
+	})
+
+	t.Run("skipped channel", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_CONFIRM", "skip")
+		elicitor := &fakeElicitor{}
+		refusal, err := ch.confirmMessage(elicitingContext(t, elicitor, true), params)
+		require.NoError(t, err)
+		assert.Empty(t, refusal)
+		assert.Empty(t, elicitor.asked)
+	})
+}
@@ -3,22 +3,29 @@ package handler
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/blockkit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/scanner"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/slack-go/slack"
 	slackGoUtil "github.com/takara2314/slack-go-util"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -55,6 +62,7 @@ type Message struct {
 	ThreadTruncated  bool   `json:"threadTruncated,omitempty"`
 	ImageCount       int    `json:"imageCount,omitempty"`
 	ImageRefs        string `json:"imageRefs,omitempty"`
+	FileRefs         string `json:"fileRefs,omitempty"`
 }
 
 type User struct {
@@ -87,21 +95,32 @@ type searchParams struct {
 	maxRepliesPerThread int
 }
 
+var errBroadcastDenied = errors.New("messages may not mention @here, @channel or @everyone, " +
+	"broadcasts are denied by SLACK_MCP_ADD_MESSAGE_BROADCAST")
+
+var errContentType = errors.New("content_type must be one of 'text/plain', 'text/markdown', '" +
+	blockkit.BlocksContentType + "' or '" + blockkit.AttachmentsContentType + "'")
+
 type addMessageParams struct {
 	channel     string
 	threadTs    string
 	text        string
 	contentType string
+	dryRun      bool
 }
 
 type ConversationsHandler struct {
 	apiProvider *provider.ApiProvider
+	approvals   *approval.Queue
 	logger      *zap.Logger
 }
 
-func NewConversationsHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *ConversationsHandler {
+// NewConversationsHandler returns the conversations handler. When approvals is not nil,
+// messages are queued for human review instead of being posted.
+func NewConversationsHandler(apiProvider *provider.ApiProvider, approvals *approval.Queue, logger *zap.Logger) *ConversationsHandler {
 	return &ConversationsHandler{
 		apiProvider: apiProvider,
+		approvals:   approvals,
 		logger:      logger,
 	}
 }
@@ -166,64 +185,64 @@ func (ch *ConversationsHandler) UsersResource(ctx context.Context, request mcp.R
 	}, nil
 }
 
-// ConversationsAddMessageHandler posts a message and returns it as CSV
+// ConversationsAddMessageHandler posts a message and returns it as CSV, or queues it for
+// approval when an approval queue is configured
 func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsAddMessageHandler called", zap.Any("params", request.Params))
 
-	params, err := ch.parseParamsToolAddMessage(request)
+	params, err := ch.parseParamsToolAddMessage(ctx, request)
 	if err != nil {
 		ch.logger.Error("Failed to parse add-message params", zap.Error(err))
 		return nil, err
 	}
 
-	var options []slack.MsgOption
-	if params.threadTs != "" {
-		options = append(options, slack.MsgOptionTS(params.threadTs))
+	scanned, err := scanOutgoing(ch.apiProvider, params.channel, params.text)
+	if err != nil {
+		ch.logger.Error("Failed to scan outgoing message", zap.Error(err))
+		return nil, err
 	}
-
-	switch params.contentType {
-	case "text/plain":
-		options = append(options, slack.MsgOptionDisableMarkdown())
-		options = append(options, slack.MsgOptionText(params.text, false))
-	case "text/markdown":
-		blocks, err := slackGoUtil.ConvertMarkdownTextToBlocks(params.text)
-		if err != nil {
-			ch.logger.Warn("Markdown parsing error", zap.Error(err))
-			options = append(options, slack.MsgOptionDisableMarkdown())
-			options = append(options, slack.MsgOptionText(params.text, false))
-		} else {
-			options = append(options, slack.MsgOptionBlocks(blocks...))
+	if len(scanned.findings) > 0 {
+		ch.logger.Warn("Sensitive content found in outgoing message",
+			zap.String("channel", params.channel),
+			zap.String("action", scanned.action),
+			zap.String("findings", scanner.Summary(scanned.findings)),
+		)
+		if scanned.blocked() {
+			return mcp.NewToolResultError(blockedMessage(params.channel, scanned.findings)), nil
 		}
-	default:
-		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
 	}
+	params.text = scanned.text
 
-	unfurlOpt := os.Getenv("SLACK_MCP_ADD_MESSAGE_UNFURLING")
-	if text.IsUnfurlingEnabled(params.text, unfurlOpt, ch.logger) {
-		options = append(options, slack.MsgOptionEnableLinkUnfurl())
-	} else {
-		options = append(options, slack.MsgOptionDisableLinkUnfurl())
-		options = append(options, slack.MsgOptionDisableMediaUnfurl())
+	if err := checkBroadcast(ch.encodedText(ctx, params)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	ch.logger.Debug("Posting Slack message",
-		zap.String("channel", params.channel),
-		zap.String("thread_ts", params.threadTs),
-		zap.String("content_type", params.contentType),
-	)
-	respChannel, respTimestamp, err := ch.apiProvider.Slack().PostMessageContext(ctx, params.channel, options...)
+	if params.dryRun {
+		return ch.previewMessage(ctx, params)
+	}
+
+	auditEvent := audit.FromContext(ctx)
+	auditEvent.SetTarget(params.channel, params.text)
+
+	refusal, err := ch.confirmMessage(ctx, params)
 	if err != nil {
-		ch.logger.Error("Slack PostMessageContext failed", zap.Error(err))
 		return nil, err
 	}
+	if refusal != "" {
+		return mcp.NewToolResultError(refusal), nil
+	}
 
-	toolConfig := os.Getenv("SLACK_MCP_ADD_MESSAGE_MARK")
-	if toolConfig == "1" || toolConfig == "true" || toolConfig == "yes" {
-		err := ch.apiProvider.Slack().MarkConversationContext(ctx, params.channel, respTimestamp)
-		if err != nil {
-			ch.logger.Error("Slack MarkConversationContext failed", zap.Error(err))
-			return nil, err
-		}
+	if ch.approvals != nil {
+		return ch.enqueueMessage(ctx, params)
+	}
+
+	respChannel, respTimestamp, err := ch.postMessage(ctx, params)
+	if respTimestamp != "" {
+		auditEvent.SetTarget(respChannel, params.text)
+		auditEvent.SetResult(respTimestamp, ch.permalink(respChannel, respTimestamp, params.threadTs))
+	}
+	if err != nil {
+		return slackErrorResult(err)
 	}
 
 	// fetch the single message we just posted
@@ -237,11 +256,16 @@ func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Conte
 	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
 	if err != nil {
 		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
-	messages := ch.convertMessagesFromHistory(history.Messages, historyParams.ChannelID, false, false)
+	redactor, err := newInboundRedactor(ch.apiProvider)
+	if err != nil {
+		ch.logger.Error("Failed to set up redaction", zap.Error(err))
+		return nil, err
+	}
+	messages := ch.convertMessagesFromHistory(history.Messages, historyParams.ChannelID, false, false, redactor)
 	return marshalMessagesToCSV(messages)
 }
 
@@ -254,6 +278,11 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 		ch.logger.Error("Failed to parse history params", zap.Error(err))
 		return nil, err
 	}
+	policy := newReadPolicy(ch.apiProvider)
+	if err := policy.check(params.channel); err != nil {
+		ch.logger.Warn("Read denied by policy", zap.String("channel", params.channel))
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	ch.logger.Debug("History params parsed",
 		zap.String("channel", params.channel),
 		zap.Int("limit", params.limit),
@@ -274,17 +303,23 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
 	if err != nil {
 		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 
 	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
+	history.Messages = policy.scrubMessages(history.Messages)
 
-	messages := ch.convertMessagesFromHistory(history.Messages, params.channel, params.activity, params.excludeBots)
+	redactor, err := newInboundRedactor(ch.apiProvider)
+	if err != nil {
+		ch.logger.Error("Failed to set up redaction", zap.Error(err))
+		return nil, err
+	}
+	messages := ch.convertMessagesFromHistory(history.Messages, params.channel, params.activity, params.excludeBots, redactor)
 
 	// Expand threads if requested
 	if params.includeThreads && len(messages) > 0 {
 		var threadErrors []string
-		messages, threadErrors = ch.expandThreads(ctx, messages, params.channel, params.excludeBots, params.maxThreads, params.maxRepliesPerThread)
+		messages, threadErrors = ch.expandThreads(ctx, messages, params.channel, params.excludeBots, params.maxThreads, params.maxRepliesPerThread, policy, redactor)
 		if len(threadErrors) > 0 {
 			ch.logger.Warn("Some threads failed to expand",
 				zap.Int("error_count", len(threadErrors)),
@@ -372,6 +407,11 @@ func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context,
 		ch.logger.Error("Failed to parse replies params", zap.Error(err))
 		return nil, err
 	}
+	policy := newReadPolicy(ch.apiProvider)
+	if err := policy.check(params.channel); err != nil {
+		ch.logger.Warn("Read denied by policy", zap.String("channel", params.channel))
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	threadTs := request.GetString("thread_ts", "")
 	if threadTs == "" {
 		ch.logger.Error("thread_ts not provided for replies", zap.String("thread_ts", threadTs))
@@ -390,11 +430,17 @@ func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context,
 	replies, hasMore, nextCursor, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &repliesParams)
 	if err != nil {
 		ch.logger.Error("GetConversationRepliesContext failed", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 	ch.logger.Debug("Fetched conversation replies", zap.Int("count", len(replies)))
+	replies = policy.scrubMessages(replies)
 
-	messages := ch.convertMessagesFromHistory(replies, params.channel, params.activity, params.excludeBots)
+	redactor, err := newInboundRedactor(ch.apiProvider)
+	if err != nil {
+		ch.logger.Error("Failed to set up redaction", zap.Error(err))
+		return nil, err
+	}
+	messages := ch.convertMessagesFromHistory(replies, params.channel, params.activity, params.excludeBots, redactor)
 	if len(messages) > 0 && hasMore {
 		messages[len(messages)-1].Cursor = nextCursor
 	}
@@ -456,11 +502,18 @@ func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context,
 	messagesRes, _, err := ch.apiProvider.Slack().SearchContext(ctx, params.query, searchParams)
 	if err != nil {
 		ch.logger.Error("Slack SearchContext failed", zap.Error(err))
-		return nil, err
+		return slackErrorResult(err)
 	}
 	ch.logger.Debug("Search completed", zap.Int("matches", len(messagesRes.Matches)))
+	policy := newReadPolicy(ch.apiProvider)
+	messagesRes.Matches = policy.filterSearch(messagesRes.Matches)
 
-	messages := ch.convertMessagesFromSearch(messagesRes.Matches, params.excludeBots)
+	redactor, err := newInboundRedactor(ch.apiProvider)
+	if err != nil {
+		ch.logger.Error("Failed to set up redaction", zap.Error(err))
+		return nil, err
+	}
+	messages := ch.convertMessagesFromSearch(messagesRes.Matches, params.excludeBots, redactor)
 
 	// Expand threads if requested
 	if params.includeThreads && len(messages) > 0 {
@@ -468,14 +521,8 @@ func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context,
 		var threadErrors []string
 		for i, msg := range messages {
 			hasThread := msg.ThreadTs != "" && msg.ThreadTs == msg.MsgID
-			if hasThread && i < params.maxThreads {
-				repliesParams := slack.GetConversationRepliesParameters{
-					ChannelID: msg.Channel,
-					Timestamp: msg.MsgID,
-					Limit:     params.maxRepliesPerThread,
-				}
-
-				repliesResp, _, _, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &repliesParams)
+			if hasThread && i < params.maxThreads && policy.allows(msg.Channel) {
+				repliesResp, err := fetchThreadReplies(ctx, ch.apiProvider.Slack(), policy, msg.Channel, msg.MsgID, params.maxRepliesPerThread)
 				if err != nil {
 					threadErrors = append(threadErrors, fmt.Sprintf("thread %s: %v", msg.MsgID, err))
 					continue
@@ -486,7 +533,7 @@ func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context,
 					if j == 0 && reply.Timestamp == msg.MsgID {
 						continue
 					}
-					replyMsg := ch.convertSingleMessage(reply, msg.Channel, false, params.excludeBots)
+					replyMsg := ch.convertSingleMessage(reply, msg.Channel, false, params.excludeBots, redactor)
 					if replyMsg != nil {
 						replyMsg.ParentMsgId = msg.MsgID
 						replyMsg.IsThreadReply = true
@@ -510,12 +557,190 @@ func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context,
 	return marshalMessagesToCSV(messages)
 }
 
+// mentions returns the directory used to encode mentions in outgoing messages.
+// Broadcast words are encoded unless the broadcast policy keeps them as text; with
+// "deny" they are encoded so checkBroadcast refuses them.
+func (ch *ConversationsHandler) mentions(ctx context.Context) *mentionDirectory {
+	var d *mentionDirectory
+	if ch.apiProvider == nil {
+		d = newMentionDirectory(nil, nil, nil)
+	} else {
+		d = newMentionDirectory(ch.apiProvider.ProvideUsersMap(), ch.apiProvider.ProvideChannelsMaps(), ch.apiProvider.ProvideUserGroupsInv(ctx))
+	}
+	d.broadcast = encodesBroadcasts()
+	return d
+}
+
+// encodesBroadcasts reports whether outgoing messages keep their broadcasts: with the
+// "text" policy they are sent as plain text and notify nobody.
+func encodesBroadcasts() bool {
+	return config.Current().AddMessage.Broadcast != "text"
+}
+
+// encodedText returns the text of params as it is sent to Slack: mentions are encoded
+// in text/plain and text/markdown messages, Block Kit payloads are sent as written
+// apart from their broadcasts under the "text" policy.
+func (ch *ConversationsHandler) encodedText(ctx context.Context, params *addMessageParams) string {
+	switch params.contentType {
+	case "text/plain", "text/markdown":
+		return ch.mentions(ctx).encodeMentions(params.text)
+	}
+	if !encodesBroadcasts() {
+		return neutralizeBroadcasts(params.text)
+	}
+	return params.text
+}
+
+// checkBroadcast refuses messages that mention @here, @channel or @everyone when the
+// broadcast policy denies them.
+func checkBroadcast(payload string) error {
+	if config.Current().AddMessage.Broadcast == "deny" && hasBroadcast(payload) {
+		return errBroadcastDenied
+	}
+	return nil
+}
+
+// messageOptions renders params into the options for PostMessageContext. blocks holds
+// the rendered Block Kit payload, or nil when the message is sent as plain text.
+func (ch *ConversationsHandler) messageOptions(ctx context.Context, params *addMessageParams) (options []slack.MsgOption, blocks []slack.Block, err error) {
+	if params.threadTs != "" {
+		options = append(options, slack.MsgOptionTS(params.threadTs))
+	}
+
+	msgText := ch.encodedText(ctx, params)
+	if err := checkBroadcast(msgText); err != nil {
+		return nil, nil, err
+	}
+
+	switch params.contentType {
+	case "text/plain":
+		options = append(options, slack.MsgOptionDisableMarkdown())
+		options = append(options, slack.MsgOptionText(msgText, false))
+	case "text/markdown":
+		blocks, err = slackGoUtil.ConvertMarkdownTextToBlocks(msgText)
+		if err != nil {
+			ch.logger.Warn("Markdown parsing error", zap.Error(err))
+			blocks = nil
+			options = append(options, slack.MsgOptionDisableMarkdown())
+			options = append(options, slack.MsgOptionText(msgText, false))
+		} else {
+			splitRichTextMentions(blocks, encodesBroadcasts())
+			options = append(options, slack.MsgOptionBlocks(blocks...))
+		}
+	case blockkit.BlocksContentType:
+		msg, err := blockkit.ParseBlocks(msgText)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks = msg.Blocks
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+		options = append(options, slack.MsgOptionText(msg.Text, false))
+	case blockkit.AttachmentsContentType:
+		msg, err := blockkit.ParseAttachments(msgText)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, slack.MsgOptionAttachments(msg.Attachments...))
+		options = append(options, slack.MsgOptionText(msg.Text, false))
+	default:
+		return nil, nil, errContentType
+	}
+
+	if text.IsUnfurlingEnabled(params.text, config.Current().AddMessage.Unfurling, ch.logger) {
+		options = append(options, slack.MsgOptionEnableLinkUnfurl())
+	} else {
+		options = append(options, slack.MsgOptionDisableLinkUnfurl())
+		options = append(options, slack.MsgOptionDisableMediaUnfurl())
+	}
+	return options, blocks, nil
+}
+
+// previewMessage renders params exactly as postMessage would send them to
+// chat.postMessage, without posting.
+func (ch *ConversationsHandler) previewMessage(ctx context.Context, params *addMessageParams) (*mcp.CallToolResult, error) {
+	options, blocks, err := ch.messageOptions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, values, err := slack.UnsafeApplyMsgOptions("", params.channel, "", options...)
+	if err != nil {
+		return nil, err
+	}
+
+	request := make(map[string]any, len(values))
+	for key := range values {
+		switch key {
+		case "token":
+		case "blocks":
+			request[key] = json.RawMessage(values.Get(key))
+		default:
+			request[key] = values.Get(key)
+		}
+	}
+	plainText := values.Get("text")
+	if params.contentType == "text/markdown" && len(blocks) > 0 {
+		plainText = text.BlocksToText(blocks)
+	}
+
+	preview := map[string]any{
+		"dry_run":    true,
+		"method":     endpoint,
+		"request":    request,
+		"plain_text": plainText,
+	}
+	b, err := json.MarshalIndent(preview, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	result := mcp.NewToolResultText(string(b))
+	result.StructuredContent = preview
+	return result, nil
+}
+
+// postMessage posts params and marks the conversation as read if configured. A DM
+// that is not open yet is opened first, and params.channel set to its ID.
+func (ch *ConversationsHandler) postMessage(ctx context.Context, params *addMessageParams) (respChannel, respTimestamp string, err error) {
+	options, _, err := ch.messageOptions(ctx, params)
+	if err != nil {
+		return "", "", err
+	}
+	params.channel, err = ch.openMessageChannel(ctx, params.channel, config.Current().AddMessage.Tool, "conversations_add_message")
+	if err != nil {
+		return "", "", err
+	}
+
+	ch.logger.Debug("Posting Slack message",
+		zap.String("channel", params.channel),
+		zap.String("thread_ts", params.threadTs),
+		zap.String("content_type", params.contentType),
+	)
+	respChannel, respTimestamp, err = ch.apiProvider.Slack().PostMessageContext(ctx, params.channel, options...)
+	if err != nil {
+		ch.logger.Error("Slack PostMessageContext failed", zap.Error(err))
+		return "", "", err
+	}
+
+	if config.Current().AddMessage.Mark {
+		err := ch.apiProvider.Slack().MarkConversationContext(ctx, params.channel, respTimestamp)
+		if err != nil {
+			ch.logger.Error("Slack MarkConversationContext failed", zap.Error(err))
+			return respChannel, respTimestamp, err
+		}
+	}
+	return respChannel, respTimestamp, nil
+}
+
 func isChannelAllowed(channel string) bool {
-	config := os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL")
-	if config == "" || config == "true" || config == "1" {
+	return channelPolicyAllows(config.Current().AddMessage.Tool, channel)
+}
+
+// channelPolicyAllows reports whether a tool channel policy, such as
+// SLACK_MCP_ADD_MESSAGE_TOOL, allows channel.
+func channelPolicyAllows(policy, channel string) bool {
+	if policy == "" || policy == "true" || policy == "1" {
 		return true
 	}
-	items := strings.Split(config, ",")
+	items := strings.Split(policy, ",")
 	isNegated := strings.HasPrefix(strings.TrimSpace(items[0]), "!")
 	for _, item := range items {
 		item = strings.TrimSpace(item)
@@ -529,11 +754,18 @@ func isChannelAllowed(channel string) bool {
 			}
 		}
 	}
-	return !isNegated
+	return isNegated
 }
 
 // expandThreads fetches thread replies for messages that have threads
-func (ch *ConversationsHandler) expandThreads(ctx context.Context, messages []Message, channelID string, excludeBots bool, maxThreads int, maxRepliesPerThread int) ([]Message, []string) {
+func (ch *ConversationsHandler) expandThreads(ctx context.Context, messages []Message, channelID string, excludeBots bool, maxThreads int, maxRepliesPerThread int, policy *readPolicy, redactor *inboundRedactor) ([]Message, []string) {
+	ctx, span := tracing.Start(ctx, "expandThreads",
+		attribute.String("slack.channel", channelID),
+		attribute.Int("messages", len(messages)),
+		attribute.Int("max_threads", maxThreads),
+	)
+	defer span.End()
+
 	var result []Message
 	var errors []string
 	threadCount := 0
@@ -545,13 +777,7 @@ func (ch *ConversationsHandler) expandThreads(ctx context.Context, messages []Me
 		hasThread := msg.ThreadTs != "" && msg.ThreadTs == msg.MsgID // Parent message
 		if hasThread && threadCount < maxThreads {
 			// Fetch thread replies
-			repliesParams := slack.GetConversationRepliesParameters{
-				ChannelID: channelID,
-				Timestamp: msg.MsgID,
-				Limit:     maxRepliesPerThread,
-			}
-
-			repliesResp, _, _, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &repliesParams)
+			repliesResp, err := fetchThreadReplies(ctx, ch.apiProvider.Slack(), policy, channelID, msg.MsgID, maxRepliesPerThread)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("thread %s: %v", msg.MsgID, err))
 				continue // Skip failed thread, don't fail entire request
@@ -564,7 +790,7 @@ func (ch *ConversationsHandler) expandThreads(ctx context.Context, messages []Me
 				}
 
 				// Convert reply to Message
-				replyMsg := ch.convertSingleMessage(reply, channelID, false, excludeBots)
+				replyMsg := ch.convertSingleMessage(reply, channelID, false, excludeBots, redactor)
 				if replyMsg != nil {
 					replyMsg.ParentMsgId = msg.MsgID
 					replyMsg.IsThreadReply = true
@@ -576,11 +802,34 @@ func (ch *ConversationsHandler) expandThreads(ctx context.Context, messages []Me
 		}
 	}
 
+	span.SetAttributes(
+		attribute.Int("threads_expanded", threadCount),
+		attribute.Int("thread_errors", len(errors)),
+	)
 	return result, errors
 }
 
+// threadRepliesFetcher is the part of the Slack API used to expand threads.
+type threadRepliesFetcher interface {
+	GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
+}
+
+// fetchThreadReplies fetches the replies of a thread, dropping attachments that quote
+// conversations the read policy denies.
+func fetchThreadReplies(ctx context.Context, client threadRepliesFetcher, policy *readPolicy, channelID, threadTs string, limit int) ([]slack.Message, error) {
+	replies, _, _, err := client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTs,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy.scrubMessages(replies), nil
+}
+
 // convertSingleMessage converts a single slack.Message to Message struct
-func (ch *ConversationsHandler) convertSingleMessage(msg slack.Message, channel string, includeActivity bool, excludeBots bool) *Message {
+func (ch *ConversationsHandler) convertSingleMessage(msg slack.Message, channel string, includeActivity bool, excludeBots bool, redactor *inboundRedactor) *Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
 
 	if (msg.SubType != "" && msg.SubType != "bot_message") && !includeActivity {
@@ -612,7 +861,7 @@ func (ch *ConversationsHandler) convertSingleMessage(msg slack.Message, channel
 		return nil
 	}
 
-	msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
+	msgText := redactor.redact(channel, msg.Text+text.AttachmentsTo2CSV(msg.Text, msg.Attachments))
 
 	var reactionParts []string
 	for _, r := range msg.Reactions {
@@ -639,10 +888,11 @@ func (ch *ConversationsHandler) convertSingleMessage(msg slack.Message, channel
 		ThreadReplyCount: msg.ReplyCount,
 		ImageCount:       imageCount,
 		ImageRefs:        formatImageRefs(allImages),
+		FileRefs:         formatFileRefs(msg.Files),
 	}
 }
 
-func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack.Message, channel string, includeActivity bool, excludeBots bool) []Message {
+func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack.Message, channel string, includeActivity bool, excludeBots bool, redactor *inboundRedactor) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
 	var messages []Message
 	warn := false
@@ -678,7 +928,7 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 			continue
 		}
 
-		msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
+		msgText := redactor.redact(channel, msg.Text+text.AttachmentsTo2CSV(msg.Text, msg.Attachments))
 
 		var reactionParts []string
 		for _, r := range msg.Reactions {
@@ -705,6 +955,7 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 			ThreadReplyCount: msg.ReplyCount,
 			ImageCount:       imageCount,
 			ImageRefs:        formatImageRefs(allImages),
+			FileRefs:         formatFileRefs(msg.Files),
 		})
 	}
 
@@ -719,7 +970,7 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 	return messages
 }
 
-func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.SearchMessage, excludeBots bool) []Message {
+func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.SearchMessage, excludeBots bool, redactor *inboundRedactor) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
 	var messages []Message
 	warn := false
@@ -753,7 +1004,7 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 			continue
 		}
 
-		msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
+		msgText := redactor.redact(msg.Channel.ID, msg.Text+text.AttachmentsTo2CSV(msg.Text, msg.Attachments))
 
 		// Extract images from attachments (SearchMessage may not include Files)
 		attachmentImages := ExtractImagesFromAttachments(msg.Attachments, msg.Timestamp)
@@ -918,8 +1169,8 @@ func (ch *ConversationsHandler) parseParamsToolConversations(request mcp.CallToo
 	}, nil
 }
 
-func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRequest) (*addMessageParams, error) {
-	toolConfig := os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL")
+func (ch *ConversationsHandler) parseParamsToolAddMessage(ctx context.Context, request mcp.CallToolRequest) (*addMessageParams, error) {
+	toolConfig := config.Current().AddMessage.Tool
 	if toolConfig == "" {
 		ch.logger.Error("Add-message tool disabled by default")
 		return nil, errors.New(
@@ -935,14 +1186,9 @@ func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRe
 		ch.logger.Error("channel_id missing in add-message params")
 		return nil, errors.New("channel_id must be a string")
 	}
-	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
-		channelsMaps := ch.apiProvider.ProvideChannelsMaps()
-		chn, ok := channelsMaps.ChannelsInv[channel]
-		if !ok {
-			ch.logger.Error("Channel not found", zap.String("channel", channel))
-			return nil, fmt.Errorf("channel %q not found", channel)
-		}
-		channel = channelsMaps.Channels[chn].ID
+	channel, err := ch.resolveMessageChannel(channel)
+	if err != nil {
+		return nil, err
 	}
 	if !isChannelAllowed(channel) {
 		ch.logger.Warn("Add-message tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
@@ -962,9 +1208,21 @@ func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRe
 	}
 
 	contentType := request.GetString("content_type", "text/markdown")
-	if contentType != "text/plain" && contentType != "text/markdown" {
+	switch contentType {
+	case "text/plain", "text/markdown":
+	case blockkit.BlocksContentType:
+		if _, err := blockkit.ParseBlocks(msgText); err != nil {
+			ch.logger.Warn("Invalid Block Kit payload", zap.Error(err))
+			return nil, err
+		}
+	case blockkit.AttachmentsContentType:
+		if _, err := blockkit.ParseAttachments(msgText); err != nil {
+			ch.logger.Warn("Invalid attachments payload", zap.Error(err))
+			return nil, err
+		}
+	default:
 		ch.logger.Error("Invalid content_type", zap.String("content_type", contentType))
-		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
+		return nil, errContentType
 	}
 
 	return &addMessageParams{
@@ -972,6 +1230,7 @@ func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRe
 		threadTs:    threadTs,
 		text:        msgText,
 		contentType: contentType,
+		dryRun:      request.GetBool("dry_run", false),
 	}, nil
 }
 
@@ -1125,6 +1384,21 @@ func (ch *ConversationsHandler) paramFormatChannel(raw string) (string, error) {
 	return "", fmt.Errorf("invalid channel format: %q", raw)
 }
 
+// permalink builds the web link of a message from the workspace URL, without the
+// extra chat.getPermalink round trip. It returns "" if the workspace URL is unknown.
+func (ch *ConversationsHandler) permalink(channel, ts, threadTs string) string {
+	ar, err := ch.apiProvider.Slack().AuthTest()
+	if err != nil || ar == nil || ar.URL == "" {
+		return ""
+	}
+
+	link := fmt.Sprintf("%sarchives/%s/p%s", strings.TrimSuffix(ar.URL, "/")+"/", channel, strings.Replace(ts, ".", "", 1))
+	if threadTs != "" {
+		link += fmt.Sprintf("?thread_ts=%s&cid=%s", threadTs, channel)
+	}
+	return link
+}
+
 func marshalMessagesToCSV(messages []Message) (*mcp.CallToolResult, error) {
 	csvBytes, err := gocsv.MarshalBytes(&messages)
 	if err != nil {
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
@@ -13,12 +14,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/korotovsky/slack-mcp-server/pkg/test/util"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/packages/param"
 	"github.com/openai/openai-go/responses"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestIntegrationConversations(t *testing.T) {
@@ -784,3 +787,149 @@ func TestUnitFormatImageRefs(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitAddMessageDryRun(t *testing.T) {
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_TOOL", "C1")
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_UNFURLING", "")
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+
+	call := func(args map[string]any) (*mcp.CallToolResult, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "conversations_add_message"
+		req.Params.Arguments = args
+		return ch.ConversationsAddMessageHandler(context.Background(), req)
+	}
+
+	result, err := call(map[string]any{
+		"channel_id": "C1",
+		"thread_ts":  "1700000000.000100",
+		"payload":    "# Release\n\nShipped **v2** to https://example.com\n\n- api\n- ui",
+		"dry_run":    true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var preview struct {
+		DryRun    bool           `json:"dry_run"`
+		Method    string         `json:"method"`
+		Request   map[string]any `json:"request"`
+		PlainText string         `json:"plain_text"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview))
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, "chat.postMessage", preview.Method)
+	assert.Equal(t, "C1", preview.Request["channel"])
+	assert.Equal(t, "1700000000.000100", preview.Request["thread_ts"])
+	assert.Equal(t, "false", preview.Request["unfurl_links"])
+	assert.NotContains(t, preview.Request, "token")
+	blocks, ok := preview.Request["blocks"].([]any)
+	require.True(t, ok, "blocks must be JSON, not a string")
+	assert.Len(t, blocks, 3)
+	assert.Equal(t, "Release\n\nShipped *v2* to https://example.com\n\n• api\n• ui", preview.PlainText)
+
+	result, err = call(map[string]any{"channel_id": "C1", "payload": "plain *text*", "content_type": "text/plain", "dry_run": true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"mrkdwn": "false"`)
+
+	result, err = call(map[string]any{
+		"channel_id":   "C1",
+		"payload":      `[{"type": "section", "text": {"type": "mrkdwn", "text": "*Deploy* done"}}, {"type": "table", "rows": [[{"type": "raw_text", "text": "eu"}]]}]`,
+		"content_type": "application/vnd.slack.blocks+json",
+		"dry_run":      true,
+	})
+	require.NoError(t, err)
+	preview.Request = nil
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview))
+	assert.Equal(t, "*Deploy* done\n\n[table block]", preview.Request["text"])
+	assert.Len(t, preview.Request["blocks"], 2)
+
+	_, err = call(map[string]any{"channel_id": "C1", "payload": `[{"type": "header"}]`, "content_type": "application/vnd.slack.blocks+json", "dry_run": true})
+	assert.ErrorContains(t, err, "blocks[0].text: is required")
+
+	_, err = call(map[string]any{"channel_id": "C1", "payload": "hi", "content_type": "text/html"})
+	assert.ErrorContains(t, err, "application/vnd.slack.blocks+json")
+
+	_, err = call(map[string]any{"channel_id": "C2", "payload": "hi", "dry_run": true})
+	assert.ErrorContains(t, err, "not allowed for channel")
+}
+
+func TestUnitAddMessageBroadcastPolicy(t *testing.T) {
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_TOOL", "C1")
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+
+	call := func(payload, contentType string) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "conversations_add_message"
+		req.Params.Arguments = map[string]any{"channel_id": "C1", "payload": payload, "content_type": contentType, "dry_run": true}
+		result, err := ch.ConversationsAddMessageHandler(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	// By default broadcast words are sent as text and notify nobody.
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_BROADCAST", "")
+	result := call("heads up @channel", "text/plain")
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"text": "heads up @channel"`)
+	result = call("- heads up @here", "text/markdown")
+	require.False(t, result.IsError)
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, `"type": "broadcast"`)
+
+	// Explicit references are escaped, and stay text in rich text, which Slack does not
+	// parse.
+	for _, tt := range []struct{ payload, contentType, want string }{
+		{"heads up <!channel>", "text/plain", `"text": "heads up \u0026lt;!channel\u0026gt;"`},
+		{"heads up <!channel>", "text/markdown", `"text": "heads up \u0026lt;!channel\u0026gt;"`},
+		{"- heads up <!channel>", "text/markdown", `"text": "\u003c!channel\u003e"`},
+		{`[{"type": "section", "text": {"type": "mrkdwn", "text": "<!channel> deploy"}}]`, "application/vnd.slack.blocks+json", `"text": "\u0026lt;!channel\u0026gt; deploy"`},
+		{`[{"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [{"type": "broadcast", "range": "channel"}]}]}]`, "application/vnd.slack.blocks+json", `"text": "@channel"`},
+	} {
+		result = call(tt.payload, tt.contentType)
+		require.False(t, result.IsError, tt.payload)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, tt.want, tt.payload)
+		assert.NotContains(t, text, `"type": "broadcast"`, tt.payload)
+	}
+
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_BROADCAST", "allow")
+	result = call("- heads up @here", "text/markdown")
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"type": "broadcast"`)
+
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_BROADCAST", "deny")
+	for _, tt := range []struct{ payload, contentType string }{
+		{"heads up @channel", "text/plain"},
+		{"heads up <!everyone>", "text/markdown"},
+		{`[{"type": "section", "text": {"type": "mrkdwn", "text": "<!here> deploy"}}]`, "application/vnd.slack.blocks+json"},
+	} {
+		result = call(tt.payload, tt.contentType)
+		assert.True(t, result.IsError, tt.payload)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "SLACK_MCP_ADD_MESSAGE_BROADCAST")
+	}
+
+	result = call("the `@here` keyword notifies everyone online", "text/markdown")
+	assert.False(t, result.IsError)
+}
+
+func TestUnitIsChannelAllowed(t *testing.T) {
+	tests := []struct {
+		policy  string
+		allowed []string
+		denied  []string
+	}{
+		{"true", []string{"C1", "D1"}, nil},
+		{"C1, D1", []string{"C1", "D1"}, []string{"C2"}},
+		{"!C1,!C2", []string{"C3", "D1"}, []string{"C1", "C2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			t.Setenv("SLACK_MCP_ADD_MESSAGE_TOOL", tt.policy)
+			for _, id := range tt.allowed {
+				assert.True(t, isChannelAllowed(id), id)
+			}
+			for _, id := range tt.denied {
+				assert.False(t, isChannelAllowed(id), id)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// maxGroupDMUsers is the most users conversations.open accepts for a group DM.
+const maxGroupDMUsers = 8
+
+var userIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+
+var errOpenDMDisabled = errors.New("opening new DMs is disabled, set SLACK_MCP_ADD_MESSAGE_OPEN_DM=true to allow it")
+
+// resolveMessageChannel returns the ID of the conversation conversations_add_message
+// posts to. ref is a channel ID, a #channel or @dm name from the channels cache, or
+// one or more comma-separated users (IDs or @usernames) to message directly. When
+// there is no DM or group DM with them yet, it returns their comma-separated user IDs
+// without opening one: openMessageChannel does that once the message passed every
+// check.
+func (ch *ConversationsHandler) resolveMessageChannel(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "#") && !strings.HasPrefix(ref, "@") && !strings.Contains(ref, ",") && !userIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+	if id, ok := channelsMaps.ChannelsInv[ref]; ok {
+		return channelsMaps.Channels[id].ID, nil
+	}
+	if strings.HasPrefix(ref, "#") {
+		ch.logger.Error("Channel not found", zap.String("channel", ref))
+		return "", fmt.Errorf("channel %q not found", ref)
+	}
+
+	userIDs, err := parseUserRefs(ref, ch.apiProvider.ProvideUsersMap())
+	if err != nil {
+		return "", err
+	}
+	if id, ok := findDirectMessage(channelsMaps.Channels, userIDs); ok {
+		return id, nil
+	}
+
+	if !config.Current().AddMessage.OpenDM {
+		ch.logger.Warn("Opening new DMs is disabled", zap.Strings("users", userIDs))
+		return "", fmt.Errorf("there is no DM with %s yet: %w", strings.Join(userIDs, ", "), errOpenDMDisabled)
+	}
+	return strings.Join(userIDs, ","), nil
+}
+
+// pendingDMUsers returns the users of channel when it names a DM or group DM that is
+// not open yet, as returned by resolveMessageChannel.
+func pendingDMUsers(channel string) ([]string, bool) {
+	if channel == "" {
+		return nil, false
+	}
+	users := strings.Split(channel, ",")
+	for _, u := range users {
+		if !userIDPattern.MatchString(u) {
+			return nil, false
+		}
+	}
+	return users, true
+}
+
+// openMessageChannel opens the DM or group DM channel names when it is not open yet
+// and returns its ID, or channel unchanged. The opened conversation must still be
+// allowed by the tool channel policy.
+func (ch *ConversationsHandler) openMessageChannel(ctx context.Context, channel, policy, tool string) (string, error) {
+	userIDs, ok := pendingDMUsers(channel)
+	if !ok {
+		return channel, nil
+	}
+	if !config.Current().AddMessage.OpenDM {
+		return "", fmt.Errorf("there is no DM with %s yet: %w", strings.Join(userIDs, ", "), errOpenDMDisabled)
+	}
+
+	opened, err := ch.apiProvider.OpenConversation(ctx, userIDs)
+	if err != nil {
+		ch.logger.Error("Slack OpenConversationContext failed", zap.Strings("users", userIDs), zap.Error(err))
+		return "", fmt.Errorf("failed to open a DM with %s: %w", strings.Join(userIDs, ", "), err)
+	}
+	if !channelPolicyAllows(policy, opened.ID) {
+		ch.logger.Warn("Opened conversation not allowed by policy", zap.String("channel", opened.ID), zap.String("policy", policy))
+		return "", fmt.Errorf("%s tool is not allowed for channel %q, applied policy: %s", tool, opened.ID, policy)
+	}
+	return opened.ID, nil
+}
+
+// parseUserRefs resolves comma-separated user IDs and @usernames to user IDs.
+func parseUserRefs(ref string, users *provider.UsersCache) ([]string, error) {
+	var ids []string
+	for _, part := range strings.Split(ref, ",") {
+		part = strings.TrimSpace(part)
+		var id string
+		switch {
+		case part == "":
+			continue
+		case strings.HasPrefix(part, "@"):
+			id = users.UsersInv[strings.TrimPrefix(part, "@")]
+		case userIDPattern.MatchString(part):
+			id = part
+		}
+		if id == "" {
+			return nil, fmt.Errorf("user %q not found, use a user ID like U1234567890 or @username", part)
+		}
+		if u, ok := users.Users[id]; ok && u.Deleted {
+			return nil, fmt.Errorf("user %q is deactivated", part)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("channel_id must name a channel or at least one user")
+	}
+	if len(ids) > maxGroupDMUsers {
+		return nil, fmt.Errorf("a group DM can have at most %d other users, got %d", maxGroupDMUsers, len(ids))
+	}
+	return ids, nil
+}
+
+// findDirectMessage returns the cached DM with the single user in userIDs, or the
+// group DM whose members are exactly userIDs and the authenticated user.
+func findDirectMessage(channels map[string]provider.Channel, userIDs []string) (string, bool) {
+	for id, c := range channels {
+		switch {
+		case len(userIDs) == 1 && c.IsIM && c.User == userIDs[0]:
+			return id, true
+		case len(userIDs) > 1 && c.IsMpIM && len(c.Members) == len(userIDs)+1:
+			matched := true
+			for _, u := range userIDs {
+				if !slices.Contains(c.Members, u) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitParseUserRefs(t *testing.T) {
+	users := &provider.UsersCache{
+		Users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+			"U9": {ID: "U9", Name: "gone", Deleted: true},
+		},
+		UsersInv: map[string]string{"alice": "U1", "bob": "U2", "gone": "U9"},
+	}
+
+	ids, err := parseUserRefs("@alice", users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"U1"}, ids)
+
+	ids, err = parseUserRefs("@alice, U2,W3ENTERPRISE,@alice", users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"U1", "U2", "W3ENTERPRISE"}, ids)
+
+	_, err = parseUserRefs("@carol", users)
+	assert.ErrorContains(t, err, `user "@carol" not found`)
+
+	_, err = parseUserRefs("@gone", users)
+	assert.ErrorContains(t, err, "deactivated")
+
+	_, err = parseUserRefs(" , ", users)
+	assert.ErrorContains(t, err, "at least one user")
+
+	_, err = parseUserRefs("U01,U02,U03,U04,U05,U06,U07,U08,U09", users)
+	assert.ErrorContains(t, err, "at most 8 other users, got 9")
+}
+
+func TestUnitFindDirectMessage(t *testing.T) {
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general"},
+		"D1": {ID: "D1", Name: "@alice", IsIM: true, User: "U1"},
+		"G1": {ID: "G1", Name: "@mpdm-me--alice--bob-1", IsMpIM: true, Members: []string{"UME", "U1", "U2"}},
+	}
+
+	tests := []struct {
+		users  []string
+		want   string
+		exists bool
+	}{
+		{[]string{"U1"}, "D1", true},
+		{[]string{"U2"}, "", false},
+		{[]string{"U2", "U1"}, "G1", true},
+		{[]string{"U1", "U3"}, "", false},
+		{[]string{"U1", "U2", "U3"}, "", false},
+	}
+	for _, tt := range tests {
+		id, ok := findDirectMessage(channels, tt.users)
+		assert.Equal(t, tt.exists, ok, tt.users)
+		assert.Equal(t, tt.want, id, tt.users)
+	}
+}
+
+// TestUnitAddMessageDefersOpeningDM checks that a DM is not opened for messages that
+// are previewed or refused. The demo provider has no Slack client, so opening one
+// would panic.
+func TestUnitAddMessageDefersOpeningDM(t *testing.T) {
+	dir := t.TempDir()
+	usersCache := filepath.Join(dir, "users.json")
+	channelsCache := filepath.Join(dir, "channels.json")
+	require.NoError(t, os.WriteFile(usersCache, []byte(`[{"id":"U1","name":"alice"},{"id":"U2","name":"bob"}]`), 0o600))
+	require.NoError(t, os.WriteFile(channelsCache, []byte(`[{"id":"C1","name":"#general"}]`), 0o600))
+	t.Setenv("SLACK_MCP_XOXP_TOKEN", "demo")
+	t.Setenv("SLACK_MCP_USERS_CACHE", usersCache)
+	t.Setenv("SLACK_MCP_CHANNELS_CACHE", channelsCache)
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_TOOL", "true")
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_OPEN_DM", "true")
+
+	p := provider.New("stdio", zap.NewNop())
+	require.NoError(t, p.RefreshUsers(context.Background()))
+	require.NoError(t, p.RefreshChannels(context.Background()))
+	ch := &ConversationsHandler{apiProvider: p, logger: zap.NewNop()}
+
+	call := func(args map[string]any) (*mcp.CallToolResult, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "conversations_add_message"
+		req.Params.Arguments = args
+		return ch.ConversationsAddMessageHandler(context.Background(), req)
+	}
+
+	result, err := call(map[string]any{"channel_id": "@alice,@bob", "payload": "hi", "dry_run": true})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var preview struct {
+		Request map[string]any `json:"request"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview))
+	assert.Equal(t, "U1,U2", preview.Request["channel"])
+
+	t.Setenv("SLACK_MCP_CONFIRM_CHANNELS", "type:im=confirm")
+	result, err = call(map[string]any{"channel_id": "@alice", "payload": "hi"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "confirm messages to U1")
+
+	t.Setenv("SLACK_MCP_ADD_MESSAGE_TOOL", "C1")
+	_, err = call(map[string]any{"channel_id": "@alice", "payload": "hi"})
+	assert.ErrorContains(t, err, "not allowed")
+}
+
+func TestUnitPendingDMUsers(t *testing.T) {
+	users, ok := pendingDMUsers("U1,W2")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"U1", "W2"}, users)
+
+	for _, channel := range []string{"", "C1", "D1", "U1,C2"} {
+		_, ok := pendingDMUsers(channel)
+		assert.False(t, ok, channel)
+	}
+}
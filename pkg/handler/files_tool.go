@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/extract"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// File retrieval constants
+const (
+	MaxFileSize         = 20 * 1024 * 1024 // Largest file get_file downloads for text extraction
+	MaxInlineTextBudget = 100 * 1024       // Extracted text returned inline, in bytes
+)
+
+var errFileTooLarge = errors.New("file exceeds the download limit")
+
+type FilesHandler struct {
+	apiProvider *provider.ApiProvider
+	logger      *zap.Logger
+}
+
+func NewFilesHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *FilesHandler {
+	return &FilesHandler{
+		apiProvider: apiProvider,
+		logger:      logger,
+	}
+}
+
+// GetFileHandler fetches a Slack file by ID and returns its text: the content of text
+// files and snippets, or the text extracted from PDFs and Office documents. Images and
+// other binary files are described by their metadata only.
+func (fh *FilesHandler) GetFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fh.logger.Debug("GetFileHandler called", zap.Any("params", request.Params))
+
+	fileID := request.GetString("file_id", "")
+	if fileID == "" {
+		return mcp.NewToolResultError("file_id parameter is required"), nil
+	}
+
+	if !fh.apiProvider.CanDownloadFiles() {
+		return mcp.NewToolResultError("File downloads are not available with the configured credentials. Use OAuth tokens (xoxp/xoxb) or a browser session with both SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN."), nil
+	}
+
+	file, _, _, err := fh.apiProvider.Slack().GetFileInfoContext(ctx, fileID, 0, 0)
+	if err != nil {
+		fh.logger.Error("Failed to get file info", zap.String("file_id", fileID), zap.Error(err))
+		if res, err := slackErrorResult(err); err == nil {
+			return res, nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get file info: %v", err)), nil
+	}
+
+	if !newReadPolicy(fh.apiProvider).allowsFile(file) {
+		fh.logger.Warn("File read denied by policy", zap.String("file_id", fileID))
+		return mcp.NewToolResultError(fmt.Sprintf("File '%s' is not shared in any conversation the server read policy allows", fileID)), nil
+	}
+
+	header := fileHeader(file)
+	if isImageMimeType(file.Mimetype) {
+		return mcp.NewToolResultText(header + "\n\nThis file is an image, use get_image to view it."), nil
+	}
+	kind := extract.Classify(file.Filetype, file.Mimetype, file.Name)
+	if kind == extract.KindBinary {
+		return mcp.NewToolResultText(header + "\n\nNo text can be extracted from this file type; only its metadata is available."), nil
+	}
+	if file.Size > MaxFileSize {
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\nThe file is too large to extract text from (%d bytes, the limit is %d bytes).", header, file.Size, MaxFileSize)), nil
+	}
+
+	downloadURL := file.URLPrivateDownload
+	if downloadURL == "" {
+		downloadURL = file.URLPrivate
+	}
+	if downloadURL == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("File '%s' does not have a download URL available", file.Name)), nil
+	}
+	if !isAllowedImageHost(downloadURL) {
+		return mcp.NewToolResultError("File URL is not from an allowed Slack domain"), nil
+	}
+
+	data, err := downloadFile(ctx, fh.apiProvider.Slack(), downloadURL, MaxFileSize)
+	if err != nil {
+		fh.logger.Error("Failed to download file", zap.String("file_id", fileID), zap.Error(err))
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to download file: %v", err)), nil
+	}
+	if kind != extract.KindText && isHTMLContent(data) {
+		return mcp.NewToolResultError("Failed to download file: authentication failed, received an HTML page instead of the file"), nil
+	}
+
+	res, err := extract.Text(kind, data, MaxInlineTextBudget)
+	if errors.Is(err, extract.ErrNoText) {
+		return mcp.NewToolResultText(header + "\n\nThe file contains no extractable text."), nil
+	}
+	if err != nil {
+		fh.logger.Warn("Failed to extract text", zap.String("file_id", fileID), zap.String("kind", string(kind)), zap.Error(err))
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to extract text from '%s': %v", file.Name, err)), nil
+	}
+
+	redactor, err := newInboundRedactor(fh.apiProvider)
+	if err != nil {
+		return nil, err
+	}
+	content := redactor.redactFile(file, res.Text)
+
+	header += fmt.Sprintf("\nExtracted: %s", res.Kind)
+	if res.Truncated {
+		header += fmt.Sprintf(" (truncated to %d bytes)", MaxInlineTextBudget)
+	}
+	return mcp.NewToolResultText(header + "\n\n" + content), nil
+}
+
+// fileHeader describes a file in get_file results.
+func fileHeader(file *slack.File) string {
+	header := fmt.Sprintf("File: %s\nID: %s\nType: %s (%s)\nSize: %d bytes", file.Name, file.ID, file.Filetype, file.Mimetype, file.Size)
+	if file.Title != "" && file.Title != file.Name {
+		header += "\nTitle: " + file.Title
+	}
+	return header
+}
+
+// downloadFile downloads url, failing once more than limit bytes arrive.
+func downloadFile(ctx context.Context, slackClient SlackFileDownloader, url string, limit int) ([]byte, error) {
+	downloadCtx, cancel := context.WithTimeout(ctx, ImageDownloadTimeout)
+	defer cancel()
+
+	w := &cappedWriter{limit: limit}
+	if err := slackClient.GetFileContext(downloadCtx, url, w); err != nil {
+		if errors.Is(err, errFileTooLarge) {
+			return nil, fmt.Errorf("file exceeds the maximum allowed size of %d bytes", limit)
+		}
+		return nil, err
+	}
+	return w.data, nil
+}
+
+// cappedWriter buffers at most limit bytes.
+type cappedWriter struct {
+	data  []byte
+	limit int
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	if len(w.data)+len(p) > w.limit {
+		return 0, errFileTooLarge
+	}
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+// formatFileRefs formats the non-image files of a message into a pipe-delimited string
+// for get_file. Images are listed in imageRefs instead.
+// Format: fileId:filename:filetype:sizeBytes|fileId:filename:filetype:sizeBytes
+func formatFileRefs(files []slack.File) string {
+	var parts []string
+	for _, f := range files {
+		if f.ID == "" || isImageMimeType(f.Mimetype) {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s:%s:%d", f.ID, f.Name, f.Filetype, f.Size))
+	}
+	return strings.Join(parts, "|")
+}
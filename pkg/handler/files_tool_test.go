@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDownloader struct {
+	content string
+}
+
+func (d fakeDownloader) GetFileContext(_ context.Context, _ string, w io.Writer) error {
+	_, err := io.Copy(w, strings.NewReader(d.content))
+	return err
+}
+
+func TestUnitFormatFileRefs(t *testing.T) {
+	files := []slack.File{
+		{ID: "F1", Name: "report.pdf", Filetype: "pdf", Mimetype: "application/pdf", Size: 2048},
+		{ID: "F2", Name: "chart.png", Filetype: "png", Mimetype: "image/png", Size: 100},
+		{ID: "F3", Name: "notes.txt", Filetype: "text", Mimetype: "text/plain", Size: 12},
+		{Name: "external.doc"},
+	}
+	assert.Equal(t, "F1:report.pdf:pdf:2048|F3:notes.txt:text:12", formatFileRefs(files))
+	assert.Empty(t, formatFileRefs(nil))
+}
+
+func TestUnitDownloadFile(t *testing.T) {
+	data, err := downloadFile(context.Background(), fakeDownloader{"hello"}, "https://files.slack.com/x", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = downloadFile(context.Background(), fakeDownloader{strings.Repeat("x", 11)}, "https://files.slack.com/x", 10)
+	assert.ErrorContains(t, err, "maximum allowed size of 10 bytes")
+}
+
+func TestUnitRedactFile(t *testing.T) {
+	var disabled *inboundRedactor
+	assert.Equal(t, "mail bob@example.com", disabled.redactFile(&slack.File{}, "mail bob@example.com"))
+
+	t.Setenv("SLACK_MCP_REDACT", "true")
+	t.Setenv("SLACK_MCP_REDACT_CHANNELS", "C1")
+	r, err := newInboundRedactor(nil)
+	require.NoError(t, err)
+
+	shared := &slack.File{Channels: []string{"C2", "C1"}}
+	assert.Equal(t, "mail REDACTED_EMAIL_1", r.redactFile(shared, "mail bob@example.com"))
+
+	other := &slack.File{Channels: []string{"C2"}}
+	assert.Equal(t, "mail bob@example.com", r.redactFile(other, "mail bob@example.com"))
+
+	assert.Equal(t, "mail REDACTED_EMAIL_1", r.redactFile(&slack.File{}, "mail bob@example.com"))
+}
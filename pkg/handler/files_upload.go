@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/scanner"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// MaxUploadSize is the largest file files_upload accepts.
+const MaxUploadSize = 50 * 1024 * 1024
+
+type uploadParams struct {
+	channel        string
+	threadTs       string
+	filename       string
+	title          string
+	initialComment string
+	altText        string
+	content        []byte
+}
+
+// FilesUploadHandler uploads a file to a conversation with the files.getUploadURLExternal
+// and files.completeUploadExternal flow and returns its file ID.
+func (ch *ConversationsHandler) FilesUploadHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("FilesUploadHandler called", zap.String("channel_id", request.GetString("channel_id", "")))
+
+	if ch.approvals != nil {
+		return mcp.NewToolResultError("files_upload is not available while approval mode is enabled: uploads cannot be queued for review. Post a message with conversations_add_message instead."), nil
+	}
+
+	params, err := ch.parseParamsToolUpload(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse files-upload params", zap.Error(err))
+		return nil, err
+	}
+
+	refusal, err := ch.scanUpload(params)
+	if err != nil {
+		return nil, err
+	}
+	if refusal != "" {
+		return mcp.NewToolResultError(refusal), nil
+	}
+
+	// The initial comment is posted as a message, so it follows the same mention rules.
+	comment := ch.mentions(ctx).encodeMentions(params.initialComment)
+	if err := checkBroadcast(comment); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	params.initialComment = comment
+
+	auditEvent := audit.FromContext(ctx)
+	auditEvent.SetTarget(params.channel, string(params.content))
+
+	refusal, err = ch.confirmMessage(ctx, &addMessageParams{
+		channel:  params.channel,
+		threadTs: params.threadTs,
+		text:     uploadSummary(params),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if refusal != "" {
+		return mcp.NewToolResultError(refusal), nil
+	}
+
+	params.channel, err = ch.openMessageChannel(ctx, params.channel, config.Current().FilesUpload.Tool, "files_upload")
+	if err != nil {
+		return slackErrorResult(err)
+	}
+	auditEvent.SetTarget(params.channel, string(params.content))
+
+	ch.logger.Debug("Uploading Slack file",
+		zap.String("channel", params.channel),
+		zap.String("thread_ts", params.threadTs),
+		zap.String("filename", params.filename),
+		zap.Int("size", len(params.content)),
+	)
+	summary, err := ch.apiProvider.Slack().UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Reader:          bytes.NewReader(params.content),
+		FileSize:        len(params.content),
+		Filename:        params.filename,
+		Title:           params.title,
+		InitialComment:  params.initialComment,
+		Channel:         params.channel,
+		ThreadTimestamp: params.threadTs,
+		AltTxt:          params.altText,
+	})
+	if err != nil {
+		ch.logger.Error("Slack UploadFileV2Context failed", zap.Error(err))
+		return slackErrorResult(err)
+	}
+
+	result := map[string]any{
+		"file_id":  summary.ID,
+		"title":    summary.Title,
+		"filename": params.filename,
+		"size":     len(params.content),
+		"channel":  params.channel,
+	}
+	if params.threadTs != "" {
+		result["thread_ts"] = params.threadTs
+	}
+	// The upload succeeded; the permalink is a convenience.
+	if file, _, _, err := ch.apiProvider.Slack().GetFileInfoContext(ctx, summary.ID, 0, 0); err == nil {
+		result["permalink"] = file.Permalink
+		result["mimetype"] = file.Mimetype
+		auditEvent.SetResult("", file.Permalink)
+	} else {
+		ch.logger.Warn("Failed to get uploaded file info", zap.String("file_id", summary.ID), zap.Error(err))
+	}
+
+	text := fmt.Sprintf("Uploaded %s to %s as file %s.", params.filename, params.channel, summary.ID)
+	if strings.HasPrefix(fmt.Sprint(result["mimetype"]), "image/") {
+		text += " Use get_image with this file ID to view it."
+	}
+	res := mcp.NewToolResultText(text)
+	res.StructuredContent = result
+	return res, nil
+}
+
+// scanUpload applies the secret scan policy to the initial comment and, for text files,
+// to the file content; binary files cannot be scanned. It returns a refusal when the
+// policy blocks the upload.
+func (ch *ConversationsHandler) scanUpload(params *uploadParams) (string, error) {
+	type part struct {
+		name string
+		text *string
+	}
+	parts := []part{{"initial comment", &params.initialComment}}
+	content := string(params.content)
+	if utf8.ValidString(content) {
+		parts = append(parts, part{"file " + params.filename, &content})
+	}
+
+	for _, part := range parts {
+		scanned, err := scanOutgoing(ch.apiProvider, params.channel, *part.text)
+		if err != nil {
+			ch.logger.Error("Failed to scan outgoing file", zap.Error(err))
+			return "", err
+		}
+		if len(scanned.findings) == 0 {
+			continue
+		}
+		ch.logger.Warn("Sensitive content found in upload",
+			zap.String("channel", params.channel),
+			zap.String("part", part.name),
+			zap.String("action", scanned.action),
+			zap.String("findings", scanner.Summary(scanned.findings)),
+		)
+		if scanned.blocked() {
+			return fmt.Sprintf("File not uploaded: the %s appears to contain %s. The server secret scan policy blocks such content in %s; remove it and try again.",
+				part.name, scanner.Summary(scanned.findings), params.channel), nil
+		}
+		*part.text = scanned.text
+	}
+	params.content = []byte(content)
+	return "", nil
+}
+
+func (ch *ConversationsHandler) parseParamsToolUpload(ctx context.Context, request mcp.CallToolRequest) (*uploadParams, error) {
+	toolConfig := config.Current().FilesUpload
+	if toolConfig.Tool == "" {
+		return nil, errors.New(
+			"by default, the files_upload tool is disabled to guard Slack workspaces against accidental uploads. " +
+				"To enable it, set the SLACK_MCP_FILES_UPLOAD_TOOL environment variable to true, 1, or a comma separated list of channels " +
+				"to limit where files may be uploaded, e.g. 'SLACK_MCP_FILES_UPLOAD_TOOL=C1234567890' or 'SLACK_MCP_FILES_UPLOAD_TOOL=!C1234567890'",
+		)
+	}
+
+	channel := request.GetString("channel_id", "")
+	if channel == "" {
+		return nil, errors.New("channel_id must be a string")
+	}
+	channel, err := ch.resolveMessageChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	if !channelPolicyAllows(toolConfig.Tool, channel) {
+		ch.logger.Warn("Files-upload tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig.Tool))
+		return nil, fmt.Errorf("files_upload tool is not allowed for channel %q, applied policy: %s", channel, toolConfig.Tool)
+	}
+
+	threadTs := request.GetString("thread_ts", "")
+	if threadTs != "" && !strings.Contains(threadTs, ".") {
+		return nil, errors.New("thread_ts must be a valid timestamp in format 1234567890.123456")
+	}
+
+	params := &uploadParams{
+		channel:        channel,
+		threadTs:       threadTs,
+		filename:       request.GetString("filename", ""),
+		title:          request.GetString("title", ""),
+		initialComment: request.GetString("initial_comment", ""),
+		altText:        request.GetString("alt_text", ""),
+	}
+
+	encoded, path := request.GetString("content_base64", ""), request.GetString("path", "")
+	switch {
+	case encoded != "" && path != "":
+		return nil, errors.New("pass either content_base64 or path, not both")
+	case encoded != "":
+		if base64.StdEncoding.DecodedLen(len(encoded)) > MaxUploadSize+2 {
+			return nil, fmt.Errorf("file is too large, at most %d bytes may be uploaded", MaxUploadSize)
+		}
+		params.content, err = base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("content_base64 is not valid base64: %w", err)
+		}
+		if params.filename == "" {
+			return nil, errors.New("filename is required with content_base64")
+		}
+	case path != "":
+		params.content, err = readUploadFile(toolConfig.Dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if params.filename == "" {
+			params.filename = filepath.Base(path)
+		}
+	default:
+		return nil, errors.New("either content_base64 or path is required")
+	}
+
+	if len(params.content) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if len(params.content) > MaxUploadSize {
+		return nil, fmt.Errorf("file is too large, at most %d bytes may be uploaded", MaxUploadSize)
+	}
+	if strings.ContainsAny(params.filename, `/\`) {
+		return nil, fmt.Errorf("filename %q must not contain a path", params.filename)
+	}
+	if params.title == "" {
+		params.title = params.filename
+	}
+	return params, nil
+}
+
+// readUploadFile reads path, relative to dir unless absolute, and refuses files that
+// are not regular files inside dir once symlinks are resolved.
+func readUploadFile(dir, path string) ([]byte, error) {
+	if dir == "" {
+		return nil, errors.New("uploading by path is disabled, set SLACK_MCP_FILES_UPLOAD_DIR to the directory files may be uploaded from, or pass content_base64")
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("upload directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("file %q not found", path)
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file %q is outside the upload directory %s", path, dir)
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%q is not a regular file", path)
+	}
+	if info.Size() > MaxUploadSize {
+		return nil, fmt.Errorf("file is too large, at most %d bytes may be uploaded", MaxUploadSize)
+	}
+	return io.ReadAll(io.LimitReader(f, MaxUploadSize+1))
+}
+
+// uploadSummary describes an upload in the confirmation prompt.
+func uploadSummary(params *uploadParams) string {
+	summary := fmt.Sprintf("[file: %s, %d bytes]", params.filename, len(params.content))
+	if params.initialComment != "" {
+		summary += "\n\n" + params.initialComment
+	}
+	return summary
+}
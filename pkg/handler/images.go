@@ -5,34 +5,28 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"image/jpeg"
-	"image/png"
 	"io"
 	"net/url"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Image processing constants
 const (
-	MaxImageSize           = 3932160          // 3.75MB - stays under 5MB after base64 encoding
+	MaxImageSize           = 16 * 1024 * 1024 // 16MB - largest image downloaded, it is downscaled to fit the response budget
 	MaxImagesPerCall       = 10               // Maximum images per tool call
 	ImageDownloadTimeout   = 30 * time.Second // Timeout for downloading images
 	MaxConcurrentDownloads = 3                // Concurrent download limit
 	MaxInlineImageBudget   = 750 * 1024       // 750KB raw (~1MB base64) - Claude Desktop has response size limit
 )
 
-// Compression settings
-const (
-	DefaultJPEGQuality = 80 // First attempt quality
-	MinJPEGQuality     = 40 // Lowest quality to try
-	JPEGQualityStep    = 20 // Reduction per attempt
-)
-
 // SlackFileDownloader interface allows mocking the Slack file download functionality
 type SlackFileDownloader interface {
 	GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error
@@ -46,6 +40,15 @@ type ImageInfo struct {
 	Size     int    // File size in bytes
 	URL      string // URLPrivate for download
 	MsgTS    string // Message timestamp for context
+
+	Thumbnails []ImageThumbnail // Slack-generated thumbnails, largest first
+}
+
+// ImageThumbnail is a Slack-generated thumbnail of an image file
+type ImageThumbnail struct {
+	URL    string
+	Width  int
+	Height int
 }
 
 // Allowed image hosts for SSRF protection
@@ -125,18 +128,38 @@ func ExtractImagesFromMessage(msg slack.Message) []ImageInfo {
 		}
 
 		images = append(images, ImageInfo{
-			FileID:   file.ID,
-			Name:     file.Name,
-			MimeType: file.Mimetype,
-			Size:     file.Size,
-			URL:      downloadURL,
-			MsgTS:    msg.Timestamp,
+			FileID:     file.ID,
+			Name:       file.Name,
+			MimeType:   file.Mimetype,
+			Size:       file.Size,
+			URL:        downloadURL,
+			MsgTS:      msg.Timestamp,
+			Thumbnails: imageThumbnails(&file),
 		})
 	}
 
 	return images
 }
 
+// imageThumbnails returns the thumbnails Slack generated for an image file, largest
+// first. Slack only generates thumbnails smaller than the original.
+func imageThumbnails(file *slack.File) []ImageThumbnail {
+	candidates := []ImageThumbnail{
+		{file.Thumb1024, file.Thumb1024W, file.Thumb1024H},
+		{file.Thumb960, file.Thumb960W, file.Thumb960H},
+		{file.Thumb720, file.Thumb720W, file.Thumb720H},
+		{file.Thumb480, file.Thumb480W, file.Thumb480H},
+		{file.Thumb360, file.Thumb360W, file.Thumb360H},
+	}
+	var thumbs []ImageThumbnail
+	for _, t := range candidates {
+		if t.URL != "" && isAllowedImageHost(t.URL) {
+			thumbs = append(thumbs, t)
+		}
+	}
+	return thumbs
+}
+
 // ExtractImagesFromAttachments extracts image URLs from Slack message attachments
 // Only Slack-hosted URLs are included to prevent SSRF vulnerabilities
 func ExtractImagesFromAttachments(attachments []slack.Attachment, msgTS string) []ImageInfo {
@@ -252,7 +275,7 @@ func DownloadImage(ctx context.Context, slackClient SlackFileDownloader, url str
 	if !isValidImageData(data) {
 		// Check if it's HTML (indicates auth failure)
 		if isHTMLContent(data) {
-			return nil, fmt.Errorf("authentication failed: received HTML login page instead of image (browser tokens may not support file downloads)")
+			return nil, fmt.Errorf("authentication failed: received HTML login page instead of image")
 		}
 		return nil, fmt.Errorf("downloaded data is not a valid image format")
 	}
@@ -407,6 +430,12 @@ func DownloadImagesWithBudget(ctx context.Context, slackClient SlackFileDownload
 		return make(map[string][]byte), make(map[string]string), nil, nil
 	}
 
+	ctx, span := tracing.Start(ctx, "DownloadImagesWithBudget",
+		attribute.Int("images", len(images)),
+		attribute.Int("budget_bytes", budget),
+	)
+	defer span.End()
+
 	imageData := make(map[string][]byte)
 	mimeTypeOverrides := make(map[string]string) // key -> new MIME type if compression changed it
 	var skippedImages []ImageInfo
@@ -425,49 +454,110 @@ func DownloadImagesWithBudget(ctx context.Context, slackClient SlackFileDownload
 		// If budget already exceeded, skip remaining images
 		if budgetExceeded {
 			skippedImages = append(skippedImages, img)
+			metrics.ImageDownloads.WithLabelValues("skipped_budget").Inc()
 			continue
 		}
 
-		// Skip images that are known to be over the individual size limit
+		remainingBudget := budget - cumulativeSize
+
+		// Download and compress the full image unless it is known to be over the size limit
+		var data []byte
+		var mimeType string
+		var err error
 		if img.Size > MaxImageSize {
-			warnings = append(warnings, fmt.Sprintf("Skipped image: image '%s' size %d bytes exceeds limit", img.Name, img.Size))
-			continue
+			err = fmt.Errorf("image '%s' size %d bytes exceeds limit", img.Name, img.Size)
+		} else {
+			data, mimeType, err = fetchImage(ctx, slackClient, img, img.URL, img.MimeType, remainingBudget)
 		}
-
-		// Download the image
-		data, err := DownloadImage(ctx, slackClient, img.URL)
-		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("Skipped image: %v", err))
+		if err == nil && cumulativeSize+len(data) <= budget {
+			imageData[key] = data
+			if mimeType != img.MimeType {
+				mimeTypeOverrides[key] = mimeType
+			}
+			metrics.ImageDownloads.WithLabelValues("inlined").Inc()
+			cumulativeSize += len(data)
 			continue
 		}
 
-		// Calculate remaining budget for this image
-		remainingBudget := budget - cumulativeSize
-
-		// Try compression if image exceeds remaining budget
-		compResult, _ := CompressImageIfNeeded(data, img.MimeType, remainingBudget)
-		data = compResult.Data
-
-		// Track MIME type change if compression occurred
-		if compResult.WasConverted {
-			mimeTypeOverrides[key] = compResult.MimeType
+		// Fall back to the largest thumbnail that fits before skipping the image
+		if thumb, thumbData, thumbMimeType, ok := fetchThumbnail(ctx, slackClient, img, remainingBudget); ok {
+			imageData[key] = thumbData
+			mimeTypeOverrides[key] = thumbMimeType
+			warnings = append(warnings, fmt.Sprintf("Included a %dx%d thumbnail of image '%s' (%s); call get_image with size=full for the original", thumb.Width, thumb.Height, img.Name, img.FileID))
+			metrics.ImageDownloads.WithLabelValues("inlined_thumbnail").Inc()
+			cumulativeSize += len(thumbData)
+			continue
 		}
 
-		// Check if (possibly compressed) image fits within budget
-		if cumulativeSize+len(data) > budget {
+		switch {
+		case img.Size > MaxImageSize:
+			warnings = append(warnings, fmt.Sprintf("Skipped image: %v", err))
+			metrics.ImageDownloads.WithLabelValues("skipped_size").Inc()
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("Skipped image: %v", err))
+			metrics.ImageDownloads.WithLabelValues("failed").Inc()
+		default:
+			// The (possibly compressed) image does not fit within the budget
 			budgetExceeded = true
 			skippedImages = append(skippedImages, img)
-			continue
+			metrics.ImageDownloads.WithLabelValues("skipped_budget").Inc()
 		}
-
-		// Image fits within budget
-		imageData[key] = data
-		cumulativeSize += len(data)
 	}
 
+	span.SetAttributes(
+		attribute.Int("inlined", len(imageData)),
+		attribute.Int("skipped", len(skippedImages)),
+		attribute.Int("inlined_bytes", cumulativeSize),
+	)
 	return imageData, mimeTypeOverrides, skippedImages, warnings
 }
 
+// fetchImage downloads an image and compresses it to fit budget if needed, returning the
+// data and its MIME type.
+func fetchImage(ctx context.Context, slackClient SlackFileDownloader, img ImageInfo, url, mimeType string, budget int) ([]byte, string, error) {
+	dlCtx, dlSpan := tracing.Start(ctx, "DownloadImage",
+		attribute.String("slack.file_id", img.FileID),
+		attribute.String("mime_type", mimeType),
+	)
+	data, err := DownloadImage(dlCtx, slackClient, url)
+	dlSpan.SetAttributes(attribute.Int("bytes", len(data)))
+	tracing.End(dlSpan, err)
+	if err != nil {
+		return nil, "", err
+	}
+	metrics.ImageDownloadBytes.Add(float64(len(data)))
+
+	_, compSpan := tracing.Start(ctx, "CompressImageIfNeeded",
+		attribute.String("slack.file_id", img.FileID),
+		attribute.Int("budget_bytes", budget),
+	)
+	compResult, err := CompressImageIfNeeded(data, mimeType, budget)
+	compSpan.SetAttributes(
+		attribute.Bool("converted", compResult.WasConverted),
+		attribute.Int("original_bytes", compResult.OriginalSize),
+		attribute.Int("final_bytes", compResult.FinalSize),
+	)
+	tracing.End(compSpan, err)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compress image '%s': %w", img.Name, err)
+	}
+	if compResult.WasConverted && compResult.OriginalSize > 0 {
+		metrics.ImageCompressionRatio.Observe(float64(compResult.FinalSize) / float64(compResult.OriginalSize))
+	}
+	return compResult.Data, compResult.MimeType, nil
+}
+
+// fetchThumbnail returns the largest thumbnail of img that fits budget.
+func fetchThumbnail(ctx context.Context, slackClient SlackFileDownloader, img ImageInfo, budget int) (ImageThumbnail, []byte, string, bool) {
+	for _, thumb := range img.Thumbnails {
+		data, mimeType, err := fetchImage(ctx, slackClient, img, thumb.URL, guessMimeTypeFromURL(thumb.URL), budget)
+		if err == nil && len(data) <= budget {
+			return thumb, data, mimeType, true
+		}
+	}
+	return ImageThumbnail{}, nil, "", false
+}
+
 // ImagesToMCPContent converts downloaded images to MCP ImageContent
 // mimeTypeOverrides contains key -> new MIME type for images that were compressed
 func ImagesToMCPContent(images []ImageInfo, imageData map[string][]byte, mimeTypeOverrides map[string]string) []mcp.Content {
@@ -502,73 +592,3 @@ func ImagesToMCPContent(images []ImageInfo, imageData map[string][]byte, mimeTyp
 
 	return content
 }
-
-// CompressImageResult holds the result of image compression
-type CompressImageResult struct {
-	Data         []byte
-	MimeType     string
-	WasConverted bool
-	OriginalSize int
-	FinalSize    int
-}
-
-// compressPNGToJPEG converts PNG image data to JPEG at the specified quality (1-100)
-func compressPNGToJPEG(pngData []byte, quality int) ([]byte, error) {
-	// Decode PNG
-	img, err := png.Decode(bytes.NewReader(pngData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode PNG: %w", err)
-	}
-
-	// Encode as JPEG
-	var buf bytes.Buffer
-	err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
-	}
-
-	return buf.Bytes(), nil
-}
-
-// CompressImageIfNeeded converts PNG images to JPEG for size savings.
-// Always converts PNG to JPEG (typically 40-70% smaller), with progressive
-// quality reduction (80 → 60 → 40) if needed to fit within budget.
-// Non-PNG images (JPEG/GIF/WebP) are returned unchanged.
-func CompressImageIfNeeded(data []byte, mimeType string, budget int) (*CompressImageResult, error) {
-	result := &CompressImageResult{
-		Data:         data,
-		MimeType:     mimeType,
-		WasConverted: false,
-		OriginalSize: len(data),
-		FinalSize:    len(data),
-	}
-
-	// Only compress PNG (JPEG/GIF/WebP are already compressed)
-	// Always convert PNG to JPEG for size savings, even if under budget
-	if mimeType != "image/png" {
-		return result, nil
-	}
-
-	// Try progressive quality levels: 80, 60, 40
-	// Start with quality 80 (best quality), reduce if needed to fit budget
-	qualities := []int{DefaultJPEGQuality, DefaultJPEGQuality - JPEGQualityStep, MinJPEGQuality}
-
-	for _, quality := range qualities {
-		compressed, err := compressPNGToJPEG(data, quality)
-		if err != nil {
-			continue // Try next quality level
-		}
-
-		// If under budget OR this is the last quality level, use this result
-		if len(compressed) <= budget || quality == MinJPEGQuality {
-			result.Data = compressed
-			result.MimeType = "image/jpeg"
-			result.WasConverted = true
-			result.FinalSize = len(compressed)
-			return result, nil
-		}
-	}
-
-	// Fallback: compression failed entirely, return original PNG
-	return result, nil
-}
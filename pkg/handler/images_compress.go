@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// Compression settings
+const (
+	DefaultJPEGQuality = 80         // First attempt quality
+	MinJPEGQuality     = 40         // Lowest quality to try
+	MaxImagePixels     = 40_000_000 // Larger images are not decoded
+	MinImageDimension  = 256        // Downscaling to fit the budget stops at this edge length
+)
+
+// CompressImageResult holds the result of image compression
+type CompressImageResult struct {
+	Data         []byte
+	MimeType     string
+	WasConverted bool
+	OriginalSize int
+	FinalSize    int
+}
+
+// CompressImageIfNeeded prepares an image for inline use. PNGs are always converted to
+// JPEG; other images are re-encoded as JPEG when they exceed the budget or the
+// configured maximum dimension, are animated GIFs (only the first frame is kept) or are
+// JPEGs with EXIF metadata (which is stripped, after applying the orientation).
+// Re-encoded images are downscaled to the maximum dimension and encoded at the highest
+// quality between MinJPEGQuality and DefaultJPEGQuality that fits the budget, shrinking
+// further if even the lowest quality does not fit. Images that cannot be decoded are
+// returned unchanged.
+func CompressImageIfNeeded(data []byte, mimeType string, budget int) (*CompressImageResult, error) {
+	result := &CompressImageResult{
+		Data:         data,
+		MimeType:     mimeType,
+		WasConverted: false,
+		OriginalSize: len(data),
+		FinalSize:    len(data),
+	}
+	if !isImageMimeType(mimeType) {
+		return result, nil
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return result, nil
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return result, fmt.Errorf("image is too large to process (%dx%d)", cfg.Width, cfg.Height)
+	}
+
+	maxDimension := config.Current().Images.MaxDimension
+	orientation, hasEXIF := 1, false
+	if format == "jpeg" {
+		orientation, hasEXIF = jpegOrientation(data)
+	}
+	if format != "png" && len(data) <= budget && max(cfg.Width, cfg.Height) <= maxDimension &&
+		!hasEXIF && !(format == "gif" && isAnimatedGIF(data)) {
+		return result, nil
+	}
+
+	// image.Decode returns the first frame of animated GIFs.
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return result, nil
+	}
+
+	w, h := fitDimensions(cfg.Width, cfg.Height, maxDimension)
+	base := orient(scaleImage(img, w, h), orientation)
+	scaled := base
+	for {
+		encoded, fits, err := encodeJPEGWithin(scaled, budget)
+		if err != nil {
+			return result, err
+		}
+		b := scaled.Bounds()
+		if fits || max(b.Dx(), b.Dy()) <= MinImageDimension {
+			result.Data = encoded
+			result.MimeType = "image/jpeg"
+			result.WasConverted = true
+			result.FinalSize = len(encoded)
+			return result, nil
+		}
+		w, h = fitDimensions(b.Dx(), b.Dy(), max(b.Dx(), b.Dy())*3/4)
+		scaled = scaleImage(base, w, h)
+	}
+}
+
+// fitDimensions scales width and height down so the longest edge is at most maxEdge,
+// keeping the aspect ratio.
+func fitDimensions(width, height, maxEdge int) (int, int) {
+	if width <= maxEdge && height <= maxEdge {
+		return width, height
+	}
+	if width >= height {
+		return maxEdge, max(1, height*maxEdge/width)
+	}
+	return max(1, width*maxEdge/height), maxEdge
+}
+
+// scaleImage resamples img to width x height with Catmull-Rom, flattening transparency
+// onto white as JPEG has no alpha channel.
+func scaleImage(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	b := img.Bounds()
+	if b.Dx() == width && b.Dy() == height {
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	}
+	return dst
+}
+
+// encodeJPEGWithin encodes img at DefaultJPEGQuality, or at the highest lower quality
+// that fits budget. It reports false with the MinJPEGQuality encoding when nothing fits.
+func encodeJPEGWithin(img image.Image, budget int) ([]byte, bool, error) {
+	encode := func(quality int) ([]byte, error) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	best, err := encode(DefaultJPEGQuality)
+	if err != nil || len(best) <= budget {
+		return best, err == nil, err
+	}
+	best = nil
+	lo, hi := MinJPEGQuality, DefaultJPEGQuality-1
+	for lo <= hi {
+		quality := (lo + hi) / 2
+		encoded, err := encode(quality)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(encoded) <= budget {
+			best, lo = encoded, quality+1
+		} else {
+			hi = quality - 1
+		}
+	}
+	if best != nil {
+		return best, true, nil
+	}
+	lowest, err := encode(MinJPEGQuality)
+	return lowest, false, err
+}
+
+// isAnimatedGIF reports whether a GIF has more than one frame. It walks the block
+// structure without decoding any pixels and stops at the second image descriptor.
+func isAnimatedGIF(data []byte) bool {
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF8")) {
+		return false
+	}
+	i := 13
+	if data[10]&0x80 != 0 { // global color table
+		i += 3 << (data[10]&0x07 + 1)
+	}
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x2C: // image descriptor
+			if frames++; frames > 1 {
+				return true
+			}
+			if i+10 > len(data) {
+				return false
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 { // local color table
+				i += 3 << (flags&0x07 + 1)
+			}
+			i = skipGIFSubBlocks(data, i+1) // after the LZW minimum code size
+		case 0x21: // extension, after its label
+			i = skipGIFSubBlocks(data, i+2)
+		default: // trailer or corrupt data
+			return false
+		}
+	}
+	return false
+}
+
+// skipGIFSubBlocks returns the offset after the data sub-blocks starting at i.
+func skipGIFSubBlocks(data []byte, i int) int {
+	for i < len(data) {
+		n := int(data[i])
+		i += 1 + n
+		if n == 0 {
+			break
+		}
+	}
+	return i
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG and whether it has an EXIF
+// segment at all. The orientation is 1 (upright) when absent.
+func jpegOrientation(data []byte) (int, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1, false
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1, false
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan or end of image
+			return 1, false
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1, false
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:]), true
+		}
+		i += 2 + length
+	}
+	return 1, false
+}
+
+// exifOrientation reads the Orientation tag from the first IFD of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[off:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[off+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient transforms img so it displays upright for the given EXIF orientation.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise to display
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise to display
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
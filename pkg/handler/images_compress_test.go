@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tinyWebP is a 1x1 lossless WebP image.
+const tinyWebP = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+
+func createTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 7 % 256), uint8(y * 13 % 256), uint8((x * y) % 256), 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+// withEXIFOrientation inserts an APP1 EXIF segment with the given orientation after the
+// SOI marker of a JPEG.
+func withEXIFOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func decodedSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	return cfg.Width, cfg.Height
+}
+
+func TestUnitCompressImageIfNeeded(t *testing.T) {
+	smallPNG := createTestPNG(50, 50, "solid")
+	largePNG := createTestPNG(500, 500, "gradient")
+	smallJPEG := createTestJPEG(t, 100, 100)
+	largeJPEG := createTestJPEG(t, 800, 800)
+
+	tests := []struct {
+		name          string
+		data          []byte
+		mimeType      string
+		budget        int
+		wantConverted bool
+		wantMimeType  string
+		wantFits      bool
+	}{
+		{"under budget PNG still converted to JPEG", smallPNG, "image/png", len(smallPNG) + 1000, true, "image/jpeg", true},
+		{"over budget PNG gets compressed", largePNG, "image/png", len(largePNG) / 2, true, "image/jpeg", false},
+		{"under budget JPEG unchanged", smallJPEG, "image/jpeg", len(smallJPEG), false, "image/jpeg", true},
+		{"over budget JPEG recompressed", largeJPEG, "image/jpeg", len(largeJPEG) / 3, true, "image/jpeg", true},
+		{"undecodable GIF unchanged", []byte("fake gif data"), "image/gif", 1, false, "image/gif", false},
+		{"unsupported type unchanged", smallPNG, "image/bmp", 1, false, "image/bmp", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompressImageIfNeeded(tt.data, tt.mimeType, tt.budget)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantConverted, result.WasConverted)
+			assert.Equal(t, tt.wantMimeType, result.MimeType)
+			assert.Equal(t, len(tt.data), result.OriginalSize)
+			assert.Equal(t, len(result.Data), result.FinalSize)
+			if tt.wantFits {
+				assert.LessOrEqual(t, result.FinalSize, tt.budget)
+			}
+			if !tt.wantConverted {
+				assert.Equal(t, tt.data, result.Data)
+			}
+		})
+	}
+}
+
+func TestUnitCompressImageDownscales(t *testing.T) {
+	wide := createTestPNG(3000, 1000, "solid")
+
+	result, err := CompressImageIfNeeded(wide, "image/png", MaxInlineImageBudget)
+	require.NoError(t, err)
+	w, h := decodedSize(t, result.Data)
+	assert.Equal(t, []int{1568, 522}, []int{w, h})
+
+	t.Setenv("SLACK_MCP_IMAGE_MAX_DIMENSION", "500")
+	result, err = CompressImageIfNeeded(wide, "image/png", MaxInlineImageBudget)
+	require.NoError(t, err)
+	w, h = decodedSize(t, result.Data)
+	assert.Equal(t, []int{500, 166}, []int{w, h})
+
+	// Shrinks below the maximum dimension when the lowest quality does not fit.
+	noisy := createTestJPEG(t, 1200, 1200)
+	result, err = CompressImageIfNeeded(noisy, "image/jpeg", 30*1024)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, result.FinalSize, 30*1024)
+	w, _ = decodedSize(t, result.Data)
+	assert.Less(t, w, 500)
+}
+
+func TestUnitCompressImageGIFAndWebP(t *testing.T) {
+	palette := color.Palette{color.White, color.Black}
+	frame := func(c uint8) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, 40, 30), palette)
+		for i := range img.Pix {
+			img.Pix[i] = c
+		}
+		return img
+	}
+	var animated bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&animated, &gif.GIF{Image: []*image.Paletted{frame(0), frame(1)}, Delay: []int{10, 10}}))
+	var static bytes.Buffer
+	require.NoError(t, gif.Encode(&static, frame(1), nil))
+
+	result, err := CompressImageIfNeeded(animated.Bytes(), "image/gif", MaxInlineImageBudget)
+	require.NoError(t, err)
+	assert.True(t, result.WasConverted)
+	assert.Equal(t, "image/jpeg", result.MimeType)
+	first, err := jpeg.Decode(bytes.NewReader(result.Data))
+	require.NoError(t, err)
+	r, _, _, _ := first.At(20, 15).RGBA()
+	assert.Greater(t, r, uint32(0xf000), "the first, white frame is kept")
+
+	result, err = CompressImageIfNeeded(static.Bytes(), "image/gif", MaxInlineImageBudget)
+	require.NoError(t, err)
+	assert.False(t, result.WasConverted)
+
+	webp, err := base64.StdEncoding.DecodeString(tinyWebP)
+	require.NoError(t, err)
+	result, err = CompressImageIfNeeded(webp, "image/webp", MaxInlineImageBudget)
+	require.NoError(t, err)
+	assert.False(t, result.WasConverted)
+
+	result, err = CompressImageIfNeeded(webp, "image/webp", 1)
+	require.NoError(t, err)
+	assert.True(t, result.WasConverted)
+	assert.Equal(t, "image/jpeg", result.MimeType)
+}
+
+func TestUnitCompressImageEXIF(t *testing.T) {
+	plain := createTestJPEG(t, 40, 20)
+	orientation, ok := jpegOrientation(plain)
+	assert.False(t, ok)
+	assert.Equal(t, 1, orientation)
+
+	rotated := withEXIFOrientation(plain, 6)
+	orientation, ok = jpegOrientation(rotated)
+	assert.True(t, ok)
+	assert.Equal(t, 6, orientation)
+
+	result, err := CompressImageIfNeeded(rotated, "image/jpeg", MaxInlineImageBudget)
+	require.NoError(t, err)
+	assert.True(t, result.WasConverted)
+	w, h := decodedSize(t, result.Data)
+	assert.Equal(t, []int{20, 40}, []int{w, h})
+	_, ok = jpegOrientation(result.Data)
+	assert.False(t, ok, "EXIF is stripped")
+}
+
+func TestUnitOrient(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marker := color.RGBA{255, 0, 0, 255}
+	img.SetRGBA(0, 0, marker) // top-left
+
+	tests := []struct {
+		orientation int
+		w, h, x, y  int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		out := orient(img, tt.orientation)
+		assert.Equal(t, image.Rect(0, 0, tt.w, tt.h), out.Bounds(), tt.orientation)
+		assert.Equal(t, marker, out.RGBAAt(tt.x, tt.y), tt.orientation)
+	}
+}
+
+func TestUnitFitDimensions(t *testing.T) {
+	tests := []struct {
+		w, h, maxEdge, wantW, wantH int
+	}{
+		{100, 50, 200, 100, 50},
+		{4000, 3000, 1568, 1568, 1176},
+		{1000, 4000, 1000, 250, 1000},
+		{5000, 1, 100, 100, 1},
+	}
+	for _, tt := range tests {
+		w, h := fitDimensions(tt.w, tt.h, tt.maxEdge)
+		assert.Equal(t, []int{tt.wantW, tt.wantH}, []int{w, h})
+	}
+}
+
+func TestUnitIsAnimatedGIF(t *testing.T) {
+	palette := color.Palette{color.White, color.Black}
+	frame := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+	var animated bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&animated, &gif.GIF{Image: []*image.Paletted{frame, frame, frame}, Delay: []int{5, 5, 5}}))
+	var static bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&static, &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{5}, LoopCount: -1}))
+	withGlobalTable := static.Bytes()
+	var local bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&local, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{5, 5},
+		Config: image.Config{ColorModel: color.Palette{color.Black, color.White}, Width: 8, Height: 8}}))
+
+	assert.True(t, isAnimatedGIF(animated.Bytes()))
+	assert.True(t, isAnimatedGIF(local.Bytes()))
+	assert.False(t, isAnimatedGIF(withGlobalTable))
+	assert.False(t, isAnimatedGIF(animated.Bytes()[:20]))
+	assert.False(t, isAnimatedGIF([]byte("fake gif data")))
+}
+
+func TestUnitCompressImageTooLarge(t *testing.T) {
+	// A GIF header declaring a 65535x65535 logical screen, which DecodeConfig reports.
+	huge := []byte{'G', 'I', 'F', '8', '9', 'a', 0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0x3B}
+
+	_, err := CompressImageIfNeeded(huge, "image/gif", MaxInlineImageBudget)
+	assert.ErrorContains(t, err, "too large to process")
+}
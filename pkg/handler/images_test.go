@@ -11,6 +11,10 @@ import (
 	"testing"
 
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestUnitIsAllowedImageHost(t *testing.T) {
@@ -939,6 +943,47 @@ func TestUnitDownloadImagesWithBudget(t *testing.T) {
 	}
 }
 
+func TestUnitDownloadImagesWithBudgetSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	smallPNG := createTestPNG(20, 20, "solid")
+	mock := &mockSlackFileDownloader{files: map[string][]byte{
+		"https://files.slack.com/F001": smallPNG,
+	}}
+	images := []ImageInfo{
+		{FileID: "F001", Name: "img1.png", MimeType: "image/png", Size: len(smallPNG), URL: "https://files.slack.com/F001"},
+		{FileID: "F002", Name: "img2.png", MimeType: "image/png", Size: 100, URL: "https://files.slack.com/F002"},
+	}
+
+	DownloadImagesWithBudget(context.Background(), mock, images, MaxInlineImageBudget)
+
+	spans := exporter.GetSpans()
+	var names []string
+	var root tracetest.SpanStub
+	for _, s := range spans {
+		names = append(names, s.Name)
+		if s.Name == "DownloadImagesWithBudget" {
+			root = s
+		}
+	}
+	want := []string{"DownloadImage", "CompressImageIfNeeded", "DownloadImage", "DownloadImagesWithBudget"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("expected spans %v, got %v", want, names)
+	}
+	for _, s := range spans[:3] {
+		if s.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Errorf("span %s is not a child of DownloadImagesWithBudget", s.Name)
+		}
+	}
+	if spans[2].Status.Code != codes.Error {
+		t.Errorf("expected failed download span to have error status, got %v", spans[2].Status.Code)
+	}
+}
+
 // createTestPNG creates a synthetic PNG image for testing
 func createTestPNG(width, height int, pattern string) []byte {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -968,149 +1013,111 @@ func createTestPNG(width, height int, pattern string) []byte {
 	return buf.Bytes()
 }
 
-func TestUnitCompressPNGToJPEG(t *testing.T) {
-	tests := []struct {
-		name    string
-		pngData []byte
-		quality int
-		wantErr bool
-	}{
-		{
-			name:    "valid PNG at quality 80",
-			pngData: createTestPNG(100, 100, "gradient"),
-			quality: 80,
-			wantErr: false,
-		},
-		{
-			name:    "valid PNG at quality 40",
-			pngData: createTestPNG(100, 100, "gradient"),
-			quality: 40,
-			wantErr: false,
-		},
-		{
-			name:    "invalid PNG data",
-			pngData: []byte("not a png"),
-			quality: 80,
-			wantErr: true,
-		},
-		{
-			name:    "empty data",
-			pngData: []byte{},
-			quality: 80,
-			wantErr: true,
-		},
+func TestUnitImageThumbnails(t *testing.T) {
+	file := &slack.File{
+		Thumb360:   "https://files.slack.com/files-tmb/T1-F1-abc/shot_360.png",
+		Thumb360W:  360,
+		Thumb360H:  200,
+		Thumb720:   "https://files.slack.com/files-tmb/T1-F1-abc/shot_720.png",
+		Thumb720W:  720,
+		Thumb720H:  400,
+		Thumb1024:  "https://evil.example.com/shot_1024.png",
+		Thumb1024W: 1024,
+		Thumb1024H: 569,
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := compressPNGToJPEG(tt.pngData, tt.quality)
-			if tt.wantErr {
-				if err == nil {
-					t.Error("expected error but got none")
-				}
-				return
-			}
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
-				return
-			}
-
-			// Verify result is valid JPEG (starts with FFD8FF)
-			if len(result) < 3 || result[0] != 0xFF || result[1] != 0xD8 || result[2] != 0xFF {
-				t.Error("result is not valid JPEG data")
-			}
-		})
+	got := imageThumbnails(file)
+	want := []ImageThumbnail{
+		{URL: "https://files.slack.com/files-tmb/T1-F1-abc/shot_720.png", Width: 720, Height: 400},
+		{URL: "https://files.slack.com/files-tmb/T1-F1-abc/shot_360.png", Width: 360, Height: 200},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("imageThumbnails() = %v, want %v", got, want)
+	}
+	if thumbs := imageThumbnails(&slack.File{}); len(thumbs) != 0 {
+		t.Errorf("expected no thumbnails, got %v", thumbs)
 	}
 }
 
-func TestUnitCompressImageIfNeeded(t *testing.T) {
-	smallPNG := createTestPNG(50, 50, "solid")
-	largePNG := createTestPNG(500, 500, "gradient")
-
-	// Create a JPEG for testing (compress a PNG first)
-	jpegData, _ := compressPNGToJPEG(createTestPNG(100, 100, "solid"), 80)
+func TestUnitDownloadImagesWithBudgetThumbnails(t *testing.T) {
+	var noisy bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 600; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 7 % 256), uint8(y * 13 % 256), uint8((x * y) % 256), 255})
+		}
+	}
+	if err := png.Encode(&noisy, img); err != nil {
+		t.Fatal(err)
+	}
+	thumb := createTestPNG(64, 64, "solid")
 
-	tests := []struct {
-		name          string
-		data          []byte
-		mimeType      string
-		budget        int
-		wantConverted bool
-		wantMimeType  string
-	}{
-		{
-			name:          "under budget PNG still converted to JPEG",
-			data:          smallPNG,
-			mimeType:      "image/png",
-			budget:        len(smallPNG) + 1000,
-			wantConverted: true,
-			wantMimeType:  "image/jpeg",
-		},
-		{
-			name:          "over budget PNG gets compressed",
-			data:          largePNG,
-			mimeType:      "image/png",
-			budget:        len(largePNG) / 2, // Force compression
-			wantConverted: true,
-			wantMimeType:  "image/jpeg",
-		},
-		{
-			name:          "JPEG unchanged even if over budget",
-			data:          jpegData,
-			mimeType:      "image/jpeg",
-			budget:        100, // Way under budget
-			wantConverted: false,
-			wantMimeType:  "image/jpeg",
+	mock := &mockSlackFileDownloader{files: map[string][]byte{
+		"https://files.slack.com/F001":           noisy.Bytes(),
+		"https://files.slack.com/F001_720.png":   noisy.Bytes(),
+		"https://files.slack.com/F001_360.png":   thumb,
+		"https://files.slack.com/F002_1024.png":  thumb,
+		"https://files.slack.com/F003/large.png": noisy.Bytes(),
+	}}
+	images := []ImageInfo{
+		{
+			FileID: "F001", Name: "noisy.png", MimeType: "image/png", Size: noisy.Len(), URL: "https://files.slack.com/F001",
+			Thumbnails: []ImageThumbnail{
+				{URL: "https://files.slack.com/F001_720.png", Width: 720, Height: 720},
+				{URL: "https://files.slack.com/F001_360.png", Width: 360, Height: 360},
+			},
 		},
 		{
-			name:          "GIF unchanged",
-			data:          []byte("fake gif data"),
-			mimeType:      "image/gif",
-			budget:        1,
-			wantConverted: false,
-			wantMimeType:  "image/gif",
+			FileID: "F002", Name: "huge.png", MimeType: "image/png", Size: MaxImageSize + 1, URL: "https://files.slack.com/F002",
+			Thumbnails: []ImageThumbnail{{URL: "https://files.slack.com/F002_1024.png", Width: 1024, Height: 1024}},
 		},
+		{FileID: "F003", Name: "large.png", MimeType: "image/png", Size: noisy.Len(), URL: "https://files.slack.com/F003/large.png"},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := CompressImageIfNeeded(tt.data, tt.mimeType, tt.budget)
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
-				return
-			}
-
-			if result.WasConverted != tt.wantConverted {
-				t.Errorf("WasConverted = %v, want %v", result.WasConverted, tt.wantConverted)
-			}
-
-			if result.MimeType != tt.wantMimeType {
-				t.Errorf("MimeType = %v, want %v", result.MimeType, tt.wantMimeType)
-			}
+	imageData, overrides, skipped, warnings := DownloadImagesWithBudget(context.Background(), mock, images, 8*1024)
 
-			if result.OriginalSize != len(tt.data) {
-				t.Errorf("OriginalSize = %d, want %d", result.OriginalSize, len(tt.data))
-			}
-		})
+	for _, key := range []string{"F001", "F002"} {
+		if _, ok := imageData[key]; !ok {
+			t.Errorf("expected %s to be included as a thumbnail", key)
+		}
+		if overrides[key] != "image/jpeg" {
+			t.Errorf("expected %s MIME override image/jpeg, got %q", key, overrides[key])
+		}
+	}
+	if len(skipped) != 1 || skipped[0].FileID != "F003" {
+		t.Errorf("expected only F003 to be skipped, got %v", skipped)
+	}
+	if len(warnings) != 2 || !bytes.Contains([]byte(warnings[0]), []byte("thumbnail of image 'noisy.png'")) {
+		t.Errorf("expected thumbnail notes, got %v", warnings)
 	}
 }
 
-func TestUnitCompressPNGToJPEG_QualityAffectsSize(t *testing.T) {
-	png := createTestPNG(200, 200, "gradient")
+func TestUnitDownloadImagesWithBudgetCompressionError(t *testing.T) {
+	huge := []byte{'G', 'I', 'F', '8', '9', 'a', 0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0x3B}
+	thumb := createTestPNG(64, 64, "solid")
 
-	jpeg80, err := compressPNGToJPEG(png, 80)
-	if err != nil {
-		t.Fatalf("compression at 80 failed: %v", err)
+	mock := &mockSlackFileDownloader{files: map[string][]byte{
+		"https://files.slack.com/F001":         huge,
+		"https://files.slack.com/F001_720.png": thumb,
+		"https://files.slack.com/F002":         huge,
+	}}
+	images := []ImageInfo{
+		{
+			FileID: "F001", Name: "huge.gif", MimeType: "image/gif", Size: len(huge), URL: "https://files.slack.com/F001",
+			Thumbnails: []ImageThumbnail{{URL: "https://files.slack.com/F001_720.png", Width: 720, Height: 720}},
+		},
+		{FileID: "F002", Name: "other.gif", MimeType: "image/gif", Size: len(huge), URL: "https://files.slack.com/F002"},
 	}
 
-	jpeg40, err := compressPNGToJPEG(png, 40)
-	if err != nil {
-		t.Fatalf("compression at 40 failed: %v", err)
-	}
+	imageData, overrides, _, warnings := DownloadImagesWithBudget(context.Background(), mock, images, MaxInlineImageBudget)
 
-	// Lower quality should produce smaller file
-	if len(jpeg40) >= len(jpeg80) {
-		t.Errorf("quality 40 (%d bytes) should be smaller than quality 80 (%d bytes)",
-			len(jpeg40), len(jpeg80))
+	if _, ok := imageData["F001"]; !ok || overrides["F001"] != "image/jpeg" {
+		t.Errorf("expected F001 to fall back to its thumbnail, got MIME %q", overrides["F001"])
+	}
+	if _, ok := imageData["F002"]; ok {
+		t.Error("expected F002 not to be inlined")
+	}
+	if len(warnings) != 2 || !bytes.Contains([]byte(warnings[1]), []byte("failed to compress image 'other.gif'")) {
+		t.Errorf("expected a thumbnail note and a compression warning, got %v", warnings)
 	}
 }
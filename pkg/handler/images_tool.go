@@ -33,28 +33,66 @@ func (ih *ImagesHandler) GetImageHandler(ctx context.Context, request mcp.CallTo
 		return mcp.NewToolResultError("file_id parameter is required"), nil
 	}
 
+	// Parse size parameter: thumb, medium or full (default)
+	size := request.GetString("size", "full")
+	switch size {
+	case "thumb", "medium", "full":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("size must be thumb, medium or full, got '%s'", size)), nil
+	}
+
 	// Check if file downloads are supported with current auth method
 	if !ih.apiProvider.CanDownloadFiles() {
-		return mcp.NewToolResultError("Image downloads not supported with browser tokens (xoxc/xoxd). Use OAuth tokens (xoxp/xoxb) instead."), nil
+		return mcp.NewToolResultError("Image downloads are not available with the configured credentials. Use OAuth tokens (xoxp/xoxb) or a browser session with both SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN."), nil
 	}
 
 	// Get file metadata from Slack
 	file, _, _, err := ih.apiProvider.Slack().GetFileInfoContext(ctx, fileID, 0, 0)
 	if err != nil {
 		ih.logger.Error("Failed to get file info", zap.String("file_id", fileID), zap.Error(err))
+		if res, err := slackErrorResult(err); err == nil {
+			return res, nil
+		}
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get file info: %v", err)), nil
 	}
 
+	if !newReadPolicy(ih.apiProvider).allowsFile(file) {
+		ih.logger.Warn("File read denied by policy", zap.String("file_id", fileID))
+		return mcp.NewToolResultError(fmt.Sprintf("File '%s' is not shared in any conversation the server read policy allows", fileID)), nil
+	}
+
 	// Validate file is an image
 	if !isImageMimeType(file.Mimetype) {
 		return mcp.NewToolResultError(fmt.Sprintf("File '%s' is not an image (type: %s). Only image files (PNG, JPEG, GIF, WebP) can be retrieved.", file.Name, file.Mimetype)), nil
 	}
 
+	// Pick the variant: thumb is the smallest Slack thumbnail, medium the largest, full
+	// the original. Small images have no thumbnails and are always returned in full.
+	thumbs := imageThumbnails(file)
+	var thumb *ImageThumbnail
+	switch {
+	case len(thumbs) == 0:
+	case size == "thumb":
+		thumb = &thumbs[len(thumbs)-1]
+	case size == "medium":
+		thumb = &thumbs[0]
+	case file.Size > MaxImageSize:
+		// Fall back to the largest thumbnail for originals over the size limit
+		thumb = &thumbs[0]
+	}
+
 	// Get the download URL
 	downloadURL := file.URLPrivate
 	if downloadURL == "" {
 		downloadURL = file.URLPrivateDownload
 	}
+	mimeType := file.Mimetype
+	variant := "full"
+	if thumb != nil {
+		downloadURL = thumb.URL
+		mimeType = guessMimeTypeFromURL(thumb.URL)
+		variant = fmt.Sprintf("thumbnail %dx%d", thumb.Width, thumb.Height)
+	}
 	if downloadURL == "" {
 		return mcp.NewToolResultError(fmt.Sprintf("File '%s' does not have a download URL available", file.Name)), nil
 	}
@@ -65,8 +103,8 @@ func (ih *ImagesHandler) GetImageHandler(ctx context.Context, request mcp.CallTo
 	}
 
 	// Check file size before downloading
-	if file.Size > MaxImageSize {
-		return mcp.NewToolResultError(fmt.Sprintf("File '%s' is too large (%d bytes). Maximum allowed size is %d bytes (5MB).", file.Name, file.Size, MaxImageSize)), nil
+	if thumb == nil && file.Size > MaxImageSize {
+		return mcp.NewToolResultError(fmt.Sprintf("File '%s' is too large (%d bytes). Maximum allowed size is %d bytes.", file.Name, file.Size, MaxImageSize)), nil
 	}
 
 	// Download the image
@@ -77,8 +115,21 @@ func (ih *ImagesHandler) GetImageHandler(ctx context.Context, request mcp.CallTo
 	}
 
 	// Compress if needed to fit within response size limit
-	mimeType := file.Mimetype
-	compResult, _ := CompressImageIfNeeded(imageData, mimeType, MaxInlineImageBudget)
+	compResult, err := CompressImageIfNeeded(imageData, mimeType, MaxInlineImageBudget)
+	if err != nil && thumb == nil && len(thumbs) > 0 {
+		// The original cannot be processed, fall back to the largest thumbnail
+		ih.logger.Warn("Failed to compress image, using the largest thumbnail", zap.String("file_id", fileID), zap.Error(err))
+		thumb = &thumbs[0]
+		mimeType = guessMimeTypeFromURL(thumb.URL)
+		variant = fmt.Sprintf("thumbnail %dx%d", thumb.Width, thumb.Height)
+		if imageData, err = DownloadImage(ctx, ih.apiProvider.Slack(), thumb.URL); err == nil {
+			compResult, err = CompressImageIfNeeded(imageData, mimeType, MaxInlineImageBudget)
+		}
+	}
+	if err != nil {
+		ih.logger.Error("Failed to prepare image", zap.String("file_id", fileID), zap.Error(err))
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to prepare image '%s': %v", file.Name, err)), nil
+	}
 	if compResult.WasConverted {
 		ih.logger.Debug("Image compressed",
 			zap.String("file_id", fileID),
@@ -92,7 +143,7 @@ func (ih *ImagesHandler) GetImageHandler(ctx context.Context, request mcp.CallTo
 	mimeType = compResult.MimeType
 
 	// Create multi-content result with text metadata and image
-	textContent := mcp.NewTextContent(fmt.Sprintf("File: %s\nSize: %d bytes\nType: %s", file.Name, len(imageData), mimeType))
+	textContent := mcp.NewTextContent(fmt.Sprintf("File: %s\nVariant: %s\nSize: %d bytes\nType: %s", file.Name, variant, len(imageData), mimeType))
 	imageContent := mcp.NewImageContent(base64.StdEncoding.EncodeToString(imageData), mimeType)
 
 	return &mcp.CallToolResult{
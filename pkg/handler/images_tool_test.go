@@ -102,3 +102,29 @@ func TestUnitGetImageHandler_EmptyFileID(t *testing.T) {
 		t.Errorf("expected error message %q, got %q", expectedMsg, textContent.Text)
 	}
 }
+
+func TestUnitGetImageHandler_InvalidSize(t *testing.T) {
+	ih := &ImagesHandler{
+		apiProvider: nil,
+		logger:      zap.NewNop(),
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"file_id": "F123",
+		"size":    "huge",
+	}
+
+	result, err := ih.GetImageHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result == nil || !result.IsError {
+		t.Fatal("expected an error result")
+	}
+
+	expectedMsg := "size must be thumb, medium or full, got 'huge'"
+	if text := result.Content[0].(mcp.TextContent).Text; text != expectedMsg {
+		t.Errorf("expected error message %q, got %q", expectedMsg, text)
+	}
+}
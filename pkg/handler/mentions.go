@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+)
+
+// maxNameWords is the longest "@Real Name" resolved in outgoing messages.
+const maxNameWords = 4
+
+var (
+	// protectedText matches code, existing <...> references and URLs, which are
+	// never rewritten.
+	protectedText = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`|<[^<>\n]*>|https?://[^\\s<>]+")
+
+	// encodedMention matches the references produced by encodeMentions.
+	encodedMention = regexp.MustCompile(`<(@[UW][A-Z0-9]+|#[CG][A-Z0-9]+|!subteam\^[A-Z0-9]+|!here|!channel|!everyone)(?:\|[^>]*)?>`)
+
+	// broadcastRef and escapedBroadcastRef match explicit @here, @channel and
+	// @everyone references, as written and as escaped by escapeBroadcasts.
+	broadcastRef        = regexp.MustCompile(`<(!(?:here|channel|everyone)(?:\|[^<>]*)?)>`)
+	escapedBroadcastRef = regexp.MustCompile(`&lt;(!(?:here|channel|everyone)(?:\|.*?)?)&gt;`)
+
+	// broadcastPattern matches @here, @channel and @everyone, encoded as text or as
+	// Block Kit broadcast elements.
+	broadcastPattern = regexp.MustCompile(`<!(here|channel|everyone)(?:\|[^>]*)?>|"type"\s*:\s*"broadcast"`)
+)
+
+// mentionDirectory resolves the names agents write in messages to Slack IDs.
+type mentionDirectory struct {
+	// usernames maps lowercased usernames to user IDs.
+	usernames map[string]string
+	// names maps lowercased display and real names to user IDs; names shared by
+	// several users map to "" and are not resolved.
+	names map[string]string
+	// groups maps lowercased user group handles to their IDs.
+	groups map[string]string
+	// channels maps lowercased "#name" to channel IDs.
+	channels map[string]string
+	// broadcast encodes @here, @channel and @everyone. Otherwise the words are left as
+	// plain text and explicit <!here> references are escaped.
+	broadcast bool
+}
+
+func newMentionDirectory(users *provider.UsersCache, channels *provider.ChannelsCache, groups map[string]string) *mentionDirectory {
+	d := &mentionDirectory{
+		usernames: make(map[string]string),
+		names:     make(map[string]string),
+		groups:    make(map[string]string),
+		channels:  make(map[string]string),
+	}
+	if users != nil {
+		for name, id := range users.UsersInv {
+			d.usernames[strings.ToLower(name)] = id
+		}
+		for id, u := range users.Users {
+			if u.Deleted {
+				continue
+			}
+			for _, name := range []string{u.Profile.DisplayName, u.RealName} {
+				key := strings.ToLower(strings.TrimSpace(name))
+				if key == "" {
+					continue
+				}
+				if other, seen := d.names[key]; seen && other != id {
+					d.names[key] = ""
+					continue
+				}
+				d.names[key] = id
+			}
+		}
+	}
+	for handle, id := range groups {
+		d.groups[strings.ToLower(handle)] = id
+	}
+	if channels != nil {
+		for name, id := range channels.ChannelsInv {
+			if strings.HasPrefix(name, "#") {
+				d.channels[strings.ToLower(name)] = id
+			}
+		}
+	}
+	return d
+}
+
+// encodeMentions rewrites @username, @Real Name, @handle of a user group, #channel and,
+// when broadcast is set, @here/@channel/@everyone as Slack references. Names that do
+// not resolve, code spans, existing references and URLs are left unchanged, except for
+// broadcast references when broadcast is not set.
+func (d *mentionDirectory) encodeMentions(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range protectedText.FindAllStringIndex(text, -1) {
+		b.WriteString(d.encodeSegment(text[last:loc[0]]))
+		b.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(d.encodeSegment(text[last:]))
+	if !d.broadcast {
+		return escapeBroadcasts(b.String())
+	}
+	return b.String()
+}
+
+// escapeBroadcasts escapes explicit broadcast references so Slack shows them as text.
+func escapeBroadcasts(text string) string {
+	return broadcastRef.ReplaceAllString(text, "&lt;$1&gt;")
+}
+
+// neutralizeBroadcasts rewrites the broadcasts of a Block Kit or attachments payload as
+// text: broadcast elements become text elements and explicit references are escaped,
+// except in plain and rich text, which Slack does not parse. Payloads that are not
+// valid JSON are returned unchanged for the parser to report.
+func neutralizeBroadcasts(payload string) string {
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return payload
+	}
+	out, err := json.Marshal(neutralizeValue(v))
+	if err != nil {
+		return payload
+	}
+	return string(out)
+}
+
+func neutralizeValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if t["type"] == "broadcast" {
+			text := map[string]any{"type": "text", "text": fmt.Sprintf("@%v", t["range"])}
+			if style, ok := t["style"]; ok {
+				text["style"] = style
+			}
+			return text
+		}
+		literal := t["type"] == "text" || t["type"] == "plain_text"
+		for key, val := range t {
+			if key == "text" && literal {
+				continue
+			}
+			t[key] = neutralizeValue(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = neutralizeValue(t[i])
+		}
+	case string:
+		return escapeBroadcasts(t)
+	}
+	return v
+}
+
+func (d *mentionDirectory) encodeSegment(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if (s[i] == '@' || s[i] == '#') && mentionBoundary(s[:i]) {
+			if ref, n := d.resolve(s[i:]); n > 0 {
+				b.WriteString(ref)
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// mentionBoundary reports whether a mention may start after before, which rules out
+// email addresses and URL fragments.
+func mentionBoundary(before string) bool {
+	r, _ := utf8.DecodeLastRuneInString(before)
+	if r == utf8.RuneError {
+		return true
+	}
+	return !isNameRune(r) && !strings.ContainsRune("@#/&=", r)
+}
+
+// resolve returns the reference for the mention at the start of s and the number of
+// bytes it replaces, or 0 if s does not start with a known name.
+func (d *mentionDirectory) resolve(s string) (string, int) {
+	sigil, rest := s[0], s[1:]
+	words := nameWords(rest, maxNameWords)
+	if len(words) == 0 {
+		return "", 0
+	}
+
+	if sigil == '#' {
+		name := trimName(words[0])
+		if id := d.channels["#"+strings.ToLower(name)]; id != "" {
+			return fmt.Sprintf("<#%s>", id), 1 + len(name)
+		}
+		return "", 0
+	}
+
+	// The longest "@Real Name" wins over a username made of its first word.
+	for n := len(words); n > 1; n-- {
+		name := trimName(strings.Join(words[:n], " "))
+		if id := d.names[strings.ToLower(name)]; id != "" {
+			return fmt.Sprintf("<@%s>", id), 1 + len(name)
+		}
+	}
+
+	for _, name := range []string{words[0], trimName(words[0])} {
+		key := strings.ToLower(name)
+		switch {
+		case key == "here" || key == "channel" || key == "everyone":
+			if !d.broadcast {
+				return "", 0
+			}
+			return "<!" + key + ">", 1 + len(name)
+		case d.usernames[key] != "":
+			return fmt.Sprintf("<@%s>", d.usernames[key]), 1 + len(name)
+		case d.groups[key] != "":
+			return fmt.Sprintf("<!subteam^%s>", d.groups[key]), 1 + len(name)
+		case d.names[key] != "":
+			return fmt.Sprintf("<@%s>", d.names[key]), 1 + len(name)
+		}
+	}
+	return "", 0
+}
+
+// nameWords returns up to max words at the start of s that are separated by single
+// spaces.
+func nameWords(s string, max int) []string {
+	var words []string
+	start := 0
+	for len(words) < max {
+		end := start
+		for end < len(s) {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if !isNameRune(r) && !strings.ContainsRune(".'-", r) {
+				break
+			}
+			end += size
+		}
+		if end == start {
+			break
+		}
+		words = append(words, s[start:end])
+		if end >= len(s) || s[end] != ' ' {
+			break
+		}
+		start = end + 1
+	}
+	return words
+}
+
+// trimName drops punctuation that ends a sentence rather than a name.
+func trimName(name string) string {
+	return strings.TrimRight(name, ".'-")
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// hasBroadcast reports whether an outgoing payload notifies a whole channel.
+func hasBroadcast(payload string) bool {
+	return broadcastPattern.MatchString(payload)
+}
+
+// splitRichTextMentions turns the references inside rich_text text elements into
+// user, channel, user group and, when broadcast is set, broadcast elements, since rich
+// text does not parse them. Otherwise broadcast references stay text, unescaped as rich
+// text shows them literally. Preformatted text is left unchanged.
+func splitRichTextMentions(blocks []slack.Block, broadcast bool) {
+	for _, block := range blocks {
+		rt, ok := block.(*slack.RichTextBlock)
+		if !ok {
+			continue
+		}
+		for _, el := range rt.Elements {
+			splitRichTextElement(el, broadcast)
+		}
+	}
+}
+
+func splitRichTextElement(el slack.RichTextElement, broadcast bool) {
+	switch e := el.(type) {
+	case *slack.RichTextSection:
+		e.Elements = splitSectionElements(e.Elements, broadcast)
+	case *slack.RichTextQuote:
+		e.Elements = splitSectionElements(e.Elements, broadcast)
+	case *slack.RichTextList:
+		for _, item := range e.Elements {
+			splitRichTextElement(item, broadcast)
+		}
+	}
+}
+
+func splitSectionElements(elements []slack.RichTextSectionElement, broadcast bool) []slack.RichTextSectionElement {
+	var out []slack.RichTextSectionElement
+	for _, el := range mergeTextElements(elements) {
+		t, ok := el.(*slack.RichTextSectionTextElement)
+		if ok && !broadcast && escapedBroadcastRef.MatchString(t.Text) {
+			t = slack.NewRichTextSectionTextElement(escapedBroadcastRef.ReplaceAllString(t.Text, "<$1>"), t.Style)
+			el = t
+		}
+		if !ok || (t.Style != nil && t.Style.Code) {
+			out = append(out, el)
+			continue
+		}
+
+		last := 0
+		for _, m := range encodedMention.FindAllStringSubmatchIndex(t.Text, -1) {
+			if m[0] > last {
+				out = append(out, slack.NewRichTextSectionTextElement(t.Text[last:m[0]], t.Style))
+			}
+			ref := t.Text[m[2]:m[3]]
+			switch {
+			case strings.HasPrefix(ref, "@"):
+				out = append(out, slack.NewRichTextSectionUserElement(ref[1:], t.Style))
+			case strings.HasPrefix(ref, "#"):
+				// NewRichTextSectionChannelElement sets the type to "text".
+				out = append(out, &slack.RichTextSectionChannelElement{Type: slack.RTSEChannel, ChannelID: ref[1:], Style: t.Style})
+			case strings.HasPrefix(ref, "!subteam^"):
+				out = append(out, slack.NewRichTextSectionUserGroupElement(strings.TrimPrefix(ref, "!subteam^")))
+			case broadcast:
+				out = append(out, slack.NewRichTextSectionBroadcastElement(ref[1:]))
+			default:
+				out = append(out, slack.NewRichTextSectionTextElement(t.Text[m[0]:m[1]], t.Style))
+			}
+			last = m[1]
+		}
+		if last == 0 {
+			out = append(out, el)
+		} else if last < len(t.Text) {
+			out = append(out, slack.NewRichTextSectionTextElement(t.Text[last:], t.Style))
+		}
+	}
+	return out
+}
+
+// mergeTextElements joins adjacent text elements with the same style; the markdown
+// converter splits text at '<', which would break references apart.
+func mergeTextElements(elements []slack.RichTextSectionElement) []slack.RichTextSectionElement {
+	var out []slack.RichTextSectionElement
+	for _, el := range elements {
+		t, ok := el.(*slack.RichTextSectionTextElement)
+		if ok && len(out) > 0 {
+			if prev, ok := out[len(out)-1].(*slack.RichTextSectionTextElement); ok && sameStyle(prev.Style, t.Style) {
+				out[len(out)-1] = slack.NewRichTextSectionTextElement(prev.Text+t.Text, prev.Style)
+				continue
+			}
+		}
+		out = append(out, el)
+	}
+	return out
+}
+
+func sameStyle(a, b *slack.RichTextSectionTextStyle) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
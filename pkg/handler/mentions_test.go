@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMentionDirectory() *mentionDirectory {
+	users := &provider.UsersCache{
+		Users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice", RealName: "Alice Smith", Profile: slack.UserProfile{DisplayName: "ali"}},
+			"U2": {ID: "U2", Name: "bob", RealName: "Bob Stone"},
+			"U3": {ID: "U3", Name: "bob.jones", RealName: "Bob Jones"},
+			"U4": {ID: "U4", Name: "sam", RealName: "Sam Lee"},
+			"U5": {ID: "U5", Name: "sam2", RealName: "Sam Lee"},
+			"U6": {ID: "U6", Name: "gone", RealName: "Gone User", Deleted: true},
+		},
+		UsersInv: map[string]string{"alice": "U1", "bob": "U2", "bob.jones": "U3", "sam": "U4", "sam2": "U5"},
+	}
+	channels := &provider.ChannelsCache{
+		ChannelsInv: map[string]string{"#general": "C1", "#dev-ops": "C2", "@alice": "D1"},
+	}
+	d := newMentionDirectory(users, channels, map[string]string{"oncall": "S1"})
+	d.broadcast = true
+	return d
+}
+
+func TestUnitEncodeMentions(t *testing.T) {
+	d := testMentionDirectory()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"username", "ping @alice please", "ping <@U1> please"},
+		{"username case", "ping @Alice.", "ping <@U1>."},
+		{"username with dot", "cc @bob.jones", "cc <@U3>"},
+		{"real name", "thanks @Alice Smith for the fix", "thanks <@U1> for the fix"},
+		{"real name beats username", "@Bob Stone and @bob", "<@U2> and <@U2>"},
+		{"display name", "hey @ali", "hey <@U1>"},
+		{"ambiguous real name", "@Sam Lee", "<@U4> Lee"},
+		{"deleted user", "@Gone User", "@Gone User"},
+		{"user group", "paging @oncall!", "paging <!subteam^S1>!"},
+		{"broadcast", "@here and @Channel", "<!here> and <!channel>"},
+		{"channel", "see #general and #Dev-Ops.", "see <#C1> and <#C2>."},
+		{"unknown", "@nobody in #random", "@nobody in #random"},
+		{"email", "mail alice@example.com", "mail alice@example.com"},
+		{"url", "https://example.com/#general and https://x.io/@alice", "https://example.com/#general and https://x.io/@alice"},
+		{"inline code", "run `@alice #general` now", "run `@alice #general` now"},
+		{"code block", "```\n@here\n```\n@here", "```\n@here\n```\n<!here>"},
+		{"encoded", "<@U2|bob> <#C1>", "<@U2|bob> <#C1>"},
+		{"markdown", "- **@alice** owns #general", "- **<@U1>** owns <#C1>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, d.encodeMentions(tt.in))
+		})
+	}
+
+	d.broadcast = false
+	assert.Equal(t, "@here and @channel, <@U1>", d.encodeMentions("@here and @channel, @alice"))
+	assert.Equal(t, "&lt;!channel&gt; and `&lt;!here|here&gt;` <!subteam^S1>", d.encodeMentions("<!channel> and `<!here|here>` <!subteam^S1>"))
+}
+
+func TestUnitSplitRichTextMentions(t *testing.T) {
+	bold := &slack.RichTextSectionTextStyle{Bold: true}
+	blocks := []slack.Block{
+		slack.NewRichTextBlock("",
+			slack.NewRichTextList(slack.RTEListBullet, 0,
+				slack.NewRichTextSection(slack.NewRichTextSectionTextElement("ask <@U1> in <#C1>", bold)),
+			),
+			&slack.RichTextPreformatted{RichTextSection: slack.RichTextSection{
+				Type:     slack.RTEPreformatted,
+				Elements: []slack.RichTextSectionElement{slack.NewRichTextSectionTextElement("<!here>", nil)},
+			}},
+			slack.NewRichTextSection(slack.NewRichTextSectionTextElement("<!subteam^S1> <!here>", nil)),
+		),
+	}
+	splitRichTextMentions(blocks, true)
+
+	b, err := json.Marshal(blocks)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"type": "rich_text", "elements": [
+	  {"type": "rich_text_list", "style": "bullet", "indent": 0, "offset": 0, "border": 0, "elements": [{"type": "rich_text_section", "elements": [
+	    {"type": "text", "text": "ask ", "style": {"bold": true}},
+	    {"type": "user", "user_id": "U1", "style": {"bold": true}},
+	    {"type": "text", "text": " in ", "style": {"bold": true}},
+	    {"type": "channel", "channel_id": "C1", "style": {"bold": true}}
+	  ]}]},
+	  {"type": "rich_text_preformatted", "border": 0, "elements": [{"type": "text", "text": "<!here>"}]},
+	  {"type": "rich_text_section", "elements": [
+	    {"type": "usergroup", "usergroup_id": "S1"},
+	    {"type": "text", "text": " "},
+	    {"type": "broadcast", "range": "here"}
+	  ]}
+	]}]`, string(b))
+}
+
+func TestUnitSplitRichTextMentionsWithoutBroadcast(t *testing.T) {
+	blocks := []slack.Block{
+		slack.NewRichTextBlock("",
+			slack.NewRichTextSection(
+				slack.NewRichTextSectionTextElement("hi &lt;", nil),
+				slack.NewRichTextSectionTextElement("!here&gt; and <!channel> <@U1>", nil),
+			),
+		),
+	}
+	splitRichTextMentions(blocks, false)
+
+	b, err := json.Marshal(blocks)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [
+	  {"type": "text", "text": "hi "},
+	  {"type": "text", "text": "<!here>"},
+	  {"type": "text", "text": " and "},
+	  {"type": "text", "text": "<!channel>"},
+	  {"type": "text", "text": " "},
+	  {"type": "user", "user_id": "U1"}
+	]}]}]`, string(b))
+	assert.False(t, hasBroadcast(string(b)))
+}
+
+func TestUnitNeutralizeBroadcasts(t *testing.T) {
+	payload := `{"text": "fallback <!here>", "blocks": [
+	  {"type": "section", "text": {"type": "mrkdwn", "text": "<!channel> deploy"}, "fields": [{"type": "plain_text", "text": "<!here>"}]},
+	  {"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [
+	    {"type": "broadcast", "range": "everyone", "style": {"bold": true}},
+	    {"type": "text", "text": " <!here>"}
+	  ]}]}
+	]}`
+	got := neutralizeBroadcasts(payload)
+	assert.JSONEq(t, `{"text": "fallback &lt;!here&gt;", "blocks": [
+	  {"type": "section", "text": {"type": "mrkdwn", "text": "&lt;!channel&gt; deploy"}, "fields": [{"type": "plain_text", "text": "<!here>"}]},
+	  {"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [
+	    {"type": "text", "text": "@everyone", "style": {"bold": true}},
+	    {"type": "text", "text": " <!here>"}
+	  ]}]}
+	]}`, got)
+	assert.NotContains(t, got, `"broadcast"`)
+
+	assert.Equal(t, "not json", neutralizeBroadcasts("not json"))
+}
+
+func TestUnitHasBroadcast(t *testing.T) {
+	assert.True(t, hasBroadcast("hi <!channel>"))
+	assert.True(t, hasBroadcast("<!everyone|everyone>"))
+	assert.True(t, hasBroadcast(`[{"type": "broadcast", "range": "here"}]`))
+	assert.False(t, hasBroadcast("hi @here"))
+	assert.False(t, hasBroadcast("<!subteam^S1>"))
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+)
+
+// readPolicy decides which conversations tools and resources may read. Entries are
+// channel IDs, names such as "#hr-private" or "@alice", glob patterns over either
+// ("#hr-*"), or conversation types ("type:im", "type:mpim", "type:private",
+// "type:public"). Deny entries win over allow entries; a non-empty allow list denies
+// everything it does not match.
+type readPolicy struct {
+	allow    []string
+	deny     []string
+	channels map[string]provider.Channel
+}
+
+// newReadPolicy snapshots the current policy and channel cache for one request.
+func newReadPolicy(apiProvider *provider.ApiProvider) *readPolicy {
+	policy := config.Current().ReadPolicy
+	p := &readPolicy{allow: policy.Allow, deny: policy.Deny}
+	if p.active() {
+		if maps := apiProvider.ProvideChannelsMaps(); maps != nil {
+			p.channels = maps.Channels
+		}
+	}
+	return p
+}
+
+func (p *readPolicy) active() bool {
+	return len(p.allow) > 0 || len(p.deny) > 0
+}
+
+// allows reports whether the conversation with the given ID may be read. Channels
+// missing from the cache can only be matched by ID, so name and type rules fail closed
+// for them.
+func (p *readPolicy) allows(channelID string) bool {
+	if !p.active() {
+		return true
+	}
+
+	ch, known := p.channels[channelID]
+	if !known {
+		ch = provider.Channel{ID: channelID, IsIM: strings.HasPrefix(channelID, "D")}
+	}
+
+	for _, entry := range p.deny {
+		if matched, certain := matchReadEntry(entry, ch, known); matched || !certain {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, entry := range p.allow {
+		if matched, _ := matchReadEntry(entry, ch, known); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error when channelID may not be read.
+func (p *readPolicy) check(channelID string) error {
+	if p.allows(channelID) {
+		return nil
+	}
+	return fmt.Errorf("reading conversation %q is not allowed by the server read policy", channelID)
+}
+
+// matchReadEntry matches a single policy entry. certain is false when the entry needs
+// channel metadata that is not available.
+func matchReadEntry(entry string, ch provider.Channel, known bool) (matched, certain bool) {
+	if kind, ok := strings.CutPrefix(entry, "type:"); ok {
+		if !known && !ch.IsIM && !ch.IsMpIM {
+			return false, false
+		}
+		switch kind {
+		case "im":
+			return ch.IsIM, true
+		case "mpim":
+			return ch.IsMpIM, true
+		case "private":
+			return ch.IsPrivate && !ch.IsIM && !ch.IsMpIM, true
+		case "public":
+			return !ch.IsPrivate && !ch.IsIM && !ch.IsMpIM, true
+		}
+		return false, true
+	}
+
+	if strings.HasPrefix(entry, "#") || strings.HasPrefix(entry, "@") {
+		if !known {
+			return false, false
+		}
+		ok, _ := path.Match(entry, ch.Name)
+		return ok, true
+	}
+
+	ok, _ := path.Match(entry, ch.ID)
+	return ok, true
+}
+
+// filterChannels drops the channels that may not be read.
+func (p *readPolicy) filterChannels(channels []provider.Channel) []provider.Channel {
+	if !p.active() {
+		return channels
+	}
+	filtered := channels[:0:0]
+	for _, ch := range channels {
+		if p.allows(ch.ID) {
+			filtered = append(filtered, ch)
+		}
+	}
+	return filtered
+}
+
+// scrubAttachments removes attachments that quote messages from conversations that may
+// not be read, which Slack includes when a message links to another message.
+func (p *readPolicy) scrubAttachments(attachments []slack.Attachment) []slack.Attachment {
+	if !p.active() || len(attachments) == 0 {
+		return attachments
+	}
+	kept := attachments[:0:0]
+	for _, att := range attachments {
+		if channelID := archiveChannel(att.FromURL); channelID != "" && !p.allows(channelID) {
+			continue
+		}
+		kept = append(kept, att)
+	}
+	return kept
+}
+
+// scrubMessages applies scrubAttachments to every message.
+func (p *readPolicy) scrubMessages(messages []slack.Message) []slack.Message {
+	if !p.active() {
+		return messages
+	}
+	for i := range messages {
+		messages[i].Attachments = p.scrubAttachments(messages[i].Attachments)
+	}
+	return messages
+}
+
+// filterSearch drops search matches from conversations that may not be read and scrubs
+// the attachments of the rest.
+func (p *readPolicy) filterSearch(matches []slack.SearchMessage) []slack.SearchMessage {
+	if !p.active() {
+		return matches
+	}
+	kept := matches[:0:0]
+	for _, m := range matches {
+		if !p.allows(m.Channel.ID) {
+			continue
+		}
+		m.Attachments = p.scrubAttachments(m.Attachments)
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// allowsFile reports whether a file may be read: it must be shared in at least one
+// readable conversation. Files without known shares are only readable without an
+// allow list.
+func (p *readPolicy) allowsFile(file *slack.File) bool {
+	if !p.active() {
+		return true
+	}
+	shares := fileShares(file)
+	if len(shares) == 0 {
+		return len(p.allow) == 0
+	}
+	for _, id := range shares {
+		if p.allows(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileShares returns the conversations a file is shared in.
+func fileShares(file *slack.File) []string {
+	var shares []string
+	shares = append(shares, file.Channels...)
+	shares = append(shares, file.Groups...)
+	shares = append(shares, file.IMs...)
+	return shares
+}
+
+// archiveChannel extracts the channel ID from a Slack message permalink such as
+// https://acme.slack.com/archives/C123/p1700000000000100.
+func archiveChannel(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasSuffix(u.Hostname(), ".slack.com") {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "archives" {
+		return parts[1]
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var readPolicyChannels = map[string]provider.Channel{
+	"C1": {ID: "C1", Name: "#general"},
+	"C2": {ID: "C2", Name: "#hr-private", IsPrivate: true},
+	"C3": {ID: "C3", Name: "#hr-announcements"},
+	"D1": {ID: "D1", Name: "@alice", IsIM: true, IsPrivate: true},
+	"G1": {ID: "G1", Name: "@mpdm-alice--bob-1", IsMpIM: true, IsPrivate: true},
+}
+
+func TestUnitReadPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		allowed []string
+		denied  []string
+	}{
+		{"no policy", nil, nil, []string{"C1", "C2", "D1", "CUNKNOWN"}, nil},
+		{"deny by name", nil, []string{"#hr-private"}, []string{"C1", "C3", "D1"}, []string{"C2"}},
+		{"deny by glob", nil, []string{"#hr-*"}, []string{"C1", "D1"}, []string{"C2", "C3"}},
+		{"deny by id", nil, []string{"C2"}, []string{"C1", "C3"}, []string{"C2"}},
+		{"deny by type", nil, []string{"type:im", "type:mpim"}, []string{"C1", "C2"}, []string{"D1", "G1", "DUNKNOWN"}},
+		{"deny private", nil, []string{"type:private"}, []string{"C1", "D1", "G1"}, []string{"C2"}},
+		{"allow list", []string{"#general", "type:im"}, nil, []string{"C1", "D1"}, []string{"C2", "C3", "G1"}},
+		{"deny wins", []string{"#hr-*"}, []string{"C2"}, []string{"C3"}, []string{"C2", "C1"}},
+		{"unknown channel fails closed on name rules", nil, []string{"#hr-*"}, []string{"C1"}, []string{"CUNKNOWN"}},
+		{"unknown channel matched by id", nil, []string{"C9"}, []string{"CUNKNOWN"}, []string{"C9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &readPolicy{allow: tt.allow, deny: tt.deny, channels: readPolicyChannels}
+			for _, id := range tt.allowed {
+				assert.True(t, p.allows(id), "%s should be readable", id)
+			}
+			for _, id := range tt.denied {
+				assert.False(t, p.allows(id), "%s should be denied", id)
+				assert.Error(t, p.check(id))
+			}
+		})
+	}
+}
+
+func TestUnitReadPolicyFilters(t *testing.T) {
+	p := &readPolicy{deny: []string{"#hr-*"}, channels: readPolicyChannels}
+
+	t.Run("search", func(t *testing.T) {
+		matches := []slack.SearchMessage{
+			{Timestamp: "1", Channel: slack.CtxChannel{ID: "C1"}},
+			{Timestamp: "2", Channel: slack.CtxChannel{ID: "C2"}},
+			{Timestamp: "3", Channel: slack.CtxChannel{ID: "C1"}, Attachments: []slack.Attachment{
+				{FromURL: "https://acme.slack.com/archives/C2/p1700000000000100", Text: "salary data"},
+				{FromURL: "https://acme.slack.com/archives/C1/p1700000000000200", Text: "lunch"},
+			}},
+		}
+		kept := p.filterSearch(matches)
+		if assert.Len(t, kept, 2) {
+			assert.Equal(t, "1", kept[0].Timestamp)
+			assert.Len(t, kept[1].Attachments, 1)
+			assert.Equal(t, "lunch", kept[1].Attachments[0].Text)
+		}
+	})
+
+	t.Run("channels", func(t *testing.T) {
+		kept := p.filterChannels([]provider.Channel{readPolicyChannels["C1"], readPolicyChannels["C2"], readPolicyChannels["C3"]})
+		assert.Len(t, kept, 1)
+	})
+
+	t.Run("files", func(t *testing.T) {
+		assert.True(t, p.allowsFile(&slack.File{Channels: []string{"C2", "C1"}}))
+		assert.False(t, p.allowsFile(&slack.File{Channels: []string{"C2"}}))
+		assert.True(t, p.allowsFile(&slack.File{}))
+
+		allowList := &readPolicy{allow: []string{"#general"}, channels: readPolicyChannels}
+		assert.False(t, allowList.allowsFile(&slack.File{}))
+	})
+}
+
+func TestUnitArchiveChannel(t *testing.T) {
+	assert.Equal(t, "C123", archiveChannel("https://acme.slack.com/archives/C123/p1700000000000100"))
+	assert.Equal(t, "", archiveChannel("https://example.com/archives/C123/p1"))
+	assert.Equal(t, "", archiveChannel("https://acme.slack.com/files/U1/F1"))
+	assert.Equal(t, "", archiveChannel(""))
+}
+
+type fakeRepliesFetcher struct {
+	replies []slack.Message
+}
+
+func (f fakeRepliesFetcher) GetConversationRepliesContext(_ context.Context, _ *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	return f.replies, false, "", nil
+}
+
+func TestUnitFetchThreadRepliesScrubs(t *testing.T) {
+	p := &readPolicy{deny: []string{"#hr-*"}, channels: readPolicyChannels}
+	client := fakeRepliesFetcher{replies: []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1", Text: "parent"}},
+		{Msg: slack.Msg{Timestamp: "2", Text: "see this", Attachments: []slack.Attachment{
+			{FromURL: "https://acme.slack.com/archives/C2/p1700000000000100", Text: "salary data"},
+			{FromURL: "https://acme.slack.com/archives/C1/p1700000000000200", Text: "lunch"},
+		}}},
+	}}
+
+	replies, err := fetchThreadReplies(context.Background(), client, p, "C1", "1", 10)
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	if assert.Len(t, replies[1].Attachments, 1) {
+		assert.Equal(t, "lunch", replies[1].Attachments[0].Text)
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/scanner"
+	"github.com/slack-go/slack"
+)
+
+// inboundRedactor masks emails, phone numbers, secrets and custom patterns in the
+// messages returned by one tool call. Placeholders are shared by the whole response, so
+// a repeated value is masked the same way in every message. A nil inboundRedactor
+// leaves text unchanged.
+type inboundRedactor struct {
+	rules    []string
+	channels map[string]provider.Channel
+	redactor *scanner.Redactor
+}
+
+// newInboundRedactor returns nil when redaction is disabled.
+func newInboundRedactor(apiProvider *provider.ApiProvider) (*inboundRedactor, error) {
+	cfg := config.Current().Redaction
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	s, err := scanner.NewWithPersonalData(cfg.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	r := &inboundRedactor{rules: cfg.Channels, redactor: scanner.NewRedactor(s)}
+	if len(r.rules) > 0 && apiProvider != nil {
+		if maps := apiProvider.ProvideChannelsMaps(); maps != nil {
+			r.channels = maps.Channels
+		}
+	}
+	return r, nil
+}
+
+// redact masks text from the conversation channelID.
+func (r *inboundRedactor) redact(channelID, text string) string {
+	if r == nil || !r.applies(channelID) {
+		return text
+	}
+	return r.redactor.Redact(text)
+}
+
+// redactFile masks the text of file when any conversation it is shared in is redacted,
+// or when its shares are unknown.
+func (r *inboundRedactor) redactFile(file *slack.File, text string) string {
+	if r == nil {
+		return text
+	}
+	shares := fileShares(file)
+	if len(shares) == 0 {
+		return r.redactor.Redact(text)
+	}
+	for _, id := range shares {
+		if r.applies(id) {
+			return r.redactor.Redact(text)
+		}
+	}
+	return text
+}
+
+// applies reports whether messages from channelID are redacted. Channels missing from
+// the cache are redacted when a name or type rule is configured.
+func (r *inboundRedactor) applies(channelID string) bool {
+	if len(r.rules) == 0 {
+		return true
+	}
+	ch, known := r.channels[channelID]
+	if !known {
+		ch = provider.Channel{ID: channelID, IsIM: strings.HasPrefix(channelID, "D")}
+	}
+	for _, entry := range r.rules {
+		if matched, certain := matchReadEntry(entry, ch, known); matched || !certain {
+			return true
+		}
+	}
+	return false
+}
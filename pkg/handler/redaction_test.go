@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitInboundRedactor(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		r, err := newInboundRedactor(nil)
+		require.NoError(t, err)
+		assert.Nil(t, r)
+		assert.Equal(t, "mail jane@acme.com", r.redact("C1", "mail jane@acme.com"))
+	})
+
+	t.Run("stable placeholders", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_REDACT", "true")
+		t.Setenv("SLACK_MCP_REDACT_PATTERNS", `CASE-\d{5}`)
+		r, err := newInboundRedactor(nil)
+		require.NoError(t, err)
+
+		first := r.redact("C1", "<mailto:jane@acme.com|jane@acme.com> reported CASE-12345, call +1 415 555 0100")
+		second := r.redact("C1", "bob@acme.com and jane@acme.com are on CASE-12345")
+		assert.Equal(t, "<mailto:REDACTED_EMAIL_1|REDACTED_EMAIL_1> reported REDACTED_CUSTOM_PATTERN_1, call REDACTED_PHONE_NUMBER_1", first)
+		assert.Equal(t, "REDACTED_EMAIL_2 and REDACTED_EMAIL_1 are on REDACTED_CUSTOM_PATTERN_1", second)
+	})
+
+	t.Run("channel rules", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_REDACT", "true")
+		t.Setenv("SLACK_MCP_REDACT_CHANNELS", "#hr-*,type:im")
+		r, err := newInboundRedactor(nil)
+		require.NoError(t, err)
+		r.channels = readPolicyChannels
+
+		assert.Equal(t, "jane@acme.com", r.redact("C1", "jane@acme.com"))
+		assert.Equal(t, "REDACTED_EMAIL_1", r.redact("C2", "jane@acme.com"))
+		assert.Equal(t, "REDACTED_EMAIL_1", r.redact("D1", "jane@acme.com"))
+		assert.Equal(t, "REDACTED_EMAIL_1", r.redact("CUNKNOWN", "jane@acme.com"))
+	})
+}
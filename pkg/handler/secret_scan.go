@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/scanner"
+)
+
+// scanResult is the outcome of scanning an outgoing message.
+type scanResult struct {
+	action   string
+	text     string
+	findings []scanner.Finding
+}
+
+// blocked reports whether the message must not be posted.
+func (r scanResult) blocked() bool {
+	return r.action == "block" && len(r.findings) > 0
+}
+
+// scanOutgoing checks text for secrets and personal data and applies the secret scan
+// action of the target channel: "block" keeps the text unchanged for the caller to
+// reject, "redact" replaces every finding and "allow" skips the scan.
+func scanOutgoing(apiProvider *provider.ApiProvider, channelID, text string) (scanResult, error) {
+	cfg := config.Current().SecretScan
+	var channels map[string]provider.Channel
+	if len(cfg.Channels) > 0 && apiProvider != nil {
+		if maps := apiProvider.ProvideChannelsMaps(); maps != nil {
+			channels = maps.Channels
+		}
+	}
+	result := scanResult{action: scanAction(cfg, channels, channelID), text: text}
+	if result.action == "allow" {
+		return result, nil
+	}
+
+	s, err := scanner.New(cfg.Patterns)
+	if err != nil {
+		return result, err
+	}
+	result.findings = s.Scan(text)
+	if result.action == "redact" && len(result.findings) > 0 {
+		result.text = scanner.Redact(text, result.findings)
+	}
+	return result, nil
+}
+
+// scanAction returns the secret scan action for channelID.
+func scanAction(cfg config.SecretScan, channels map[string]provider.Channel, channelID string) string {
+	if action, ok := channelRuleAction(cfg.Channels, channels, channelID); ok {
+		return action
+	}
+	if cfg.Action == "" {
+		return "block"
+	}
+	return cfg.Action
+}
+
+// channelRuleAction returns the action of the first "<entry>=<action>" rule matching
+// channelID. Rules that need channel metadata missing from the cache are skipped.
+func channelRuleAction(rules []string, channels map[string]provider.Channel, channelID string) (string, bool) {
+	ch, known := channels[channelID]
+	if !known {
+		ch = provider.Channel{ID: channelID, IsIM: strings.HasPrefix(channelID, "D")}
+		if users, ok := pendingDMUsers(channelID); ok {
+			ch.IsIM, ch.IsMpIM = len(users) == 1, len(users) > 1
+		}
+	}
+
+	for _, rule := range rules {
+		entry, action, ok := strings.Cut(rule, "=")
+		if !ok {
+			continue
+		}
+		if matched, _ := matchReadEntry(strings.TrimSpace(entry), ch, known); matched {
+			return strings.TrimSpace(action), true
+		}
+	}
+	return "", false
+}
+
+// blockedMessage explains a blocked message without repeating the sensitive content.
+func blockedMessage(channelID string, findings []scanner.Finding) string {
+	return fmt.Sprintf("Message not posted: it appears to contain %s. The server secret scan policy blocks such content in %s; remove it and try again.",
+		scanner.Summary(findings), channelID)
+}
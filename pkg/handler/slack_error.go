@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+)
+
+// slackErrorHints maps Slack error codes to what the caller can do about them.
+var slackErrorHints = map[string]string{
+	"channel_not_found": "The channel does not exist or is not visible to this token. Call channels_list to look up the channel ID, or pass the name as #channel or @user.",
+	"not_in_channel":    "The Slack user or bot behind this token is not a member of the channel. Join the channel (or /invite the bot) and retry.",
+	"missing_scope":     "The token lacks an OAuth scope this call needs. Call get_capabilities to see which scopes are missing; they must be added to the Slack app.",
+	"invalid_cursor":    "The cursor is invalid or expired. Repeat the request without a cursor to start from the first page.",
+	"is_archived":       "The channel is archived and cannot be posted to. Pick another channel or ask a workspace admin to unarchive it.",
+	"thread_not_found":  "No thread exists with this thread_ts. Use the ts of the parent message from conversations_history.",
+	"message_not_found": "The message was not found; it may have been deleted. Fetch conversations_history again to get current timestamps.",
+	"file_not_found":    "The file does not exist or was deleted. Check the file ID in the imageRefs column of conversation history.",
+	"invalid_auth":      "The Slack credentials are invalid. Update the token configured for the server and restart it.",
+	"not_authed":        "No Slack credentials were sent. Check the token configured for the server.",
+	"token_revoked":     "The Slack token was revoked. Configure a new token and restart the server.",
+	"account_inactive":  "The Slack account behind this token is deactivated. Configure a token of an active account.",
+}
+
+// slackError is the structured payload returned to the model for Slack API failures.
+type slackError struct {
+	Code              string `json:"code"`
+	Message           string `json:"message"`
+	Hint              string `json:"hint,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// classifySlackError extracts the Slack error code from err. It returns false for errors
+// that did not come from the Slack API, such as network failures or invalid parameters.
+func classifySlackError(err error) (slackError, bool) {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		seconds := int(math.Ceil(rateLimited.RetryAfter.Seconds()))
+		return slackError{
+			Code:              "ratelimited",
+			Message:           err.Error(),
+			Hint:              fmt.Sprintf("Slack rate limited this request. Retry after %ds, and prefer fewer, larger requests.", seconds),
+			RetryAfterSeconds: seconds,
+		}, true
+	}
+
+	var code string
+	var slackResp slack.SlackErrorResponse
+	var edgeErr *edge.APIError
+	switch {
+	case errors.As(err, &slackResp):
+		code = slackResp.Err
+	case errors.As(err, &edgeErr):
+		code = edgeErr.Err
+	default:
+		// slack-go returns some API errors as plain errors carrying just the code.
+		if _, known := slackErrorHints[err.Error()]; known {
+			code = err.Error()
+		}
+	}
+	if code == "" {
+		return slackError{}, false
+	}
+
+	return slackError{
+		Code:    code,
+		Message: err.Error(),
+		Hint:    slackErrorHints[code],
+	}, true
+}
+
+// slackErrorResult turns a Slack API error into a tool error result carrying the error
+// code and a remediation hint, so the model can recover instead of giving up. Other
+// errors are returned unchanged.
+func slackErrorResult(err error) (*mcp.CallToolResult, error) {
+	se, ok := classifySlackError(err)
+	if !ok {
+		return nil, err
+	}
+
+	lines := []string{fmt.Sprintf("Slack API error: %s", se.Code)}
+	if se.Message != se.Code {
+		lines = append(lines, "Message: "+se.Message)
+	}
+	if se.RetryAfterSeconds > 0 {
+		lines = append(lines, fmt.Sprintf("Retry after: %s", time.Duration(se.RetryAfterSeconds)*time.Second))
+	}
+	if se.Hint != "" {
+		lines = append(lines, "Hint: "+se.Hint)
+	}
+
+	result := mcp.NewToolResultError(strings.Join(lines, "\n"))
+	result.StructuredContent = map[string]any{"error": se}
+	return result, nil
+}
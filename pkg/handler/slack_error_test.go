@@ -2,10 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSlackErrorStringUnmarshal reproduces the bug where Slack API returns
@@ -58,3 +64,46 @@ func TestSlackErrorObjectUnmarshal(t *testing.T) {
 	assert.False(t, resp.Ok)
 	assert.Equal(t, "invalid_blocks", resp.Error)
 }
+
+func TestUnitSlackErrorResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       string
+		hint       string
+		retryAfter int
+	}{
+		{"slack response", slack.SlackErrorResponse{Err: "not_in_channel"}, "not_in_channel", "Join the channel", 0},
+		{"wrapped", fmt.Errorf("history: %w", slack.SlackErrorResponse{Err: "channel_not_found"}), "channel_not_found", "channels_list", 0},
+		{"plain code", errors.New("invalid_cursor"), "invalid_cursor", "without a cursor", 0},
+		{"edge", &edge.APIError{Err: "missing_scope"}, "missing_scope", "get_capabilities", 0},
+		{"archived", slack.SlackErrorResponse{Err: "is_archived"}, "is_archived", "archived", 0},
+		{"rate limited", &slack.RateLimitedError{RetryAfter: 29500 * time.Millisecond}, "ratelimited", "Retry after 30s", 30},
+		{"unknown code", slack.SlackErrorResponse{Err: "team_added_to_org"}, "team_added_to_org", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := slackErrorResult(tt.err)
+			require.NoError(t, err)
+			require.NotNil(t, res)
+			assert.True(t, res.IsError)
+
+			text := res.Content[0].(mcp.TextContent).Text
+			assert.Contains(t, text, "Slack API error: "+tt.code)
+			if tt.hint != "" {
+				assert.Contains(t, text, tt.hint)
+			}
+
+			se := res.StructuredContent.(map[string]any)["error"].(slackError)
+			assert.Equal(t, tt.code, se.Code)
+			assert.Equal(t, tt.retryAfter, se.RetryAfterSeconds)
+		})
+	}
+}
+
+func TestUnitSlackErrorResultPassesThroughOtherErrors(t *testing.T) {
+	orig := errors.New("dial tcp: connection refused")
+	res, err := slackErrorResult(orig)
+	assert.Nil(t, res)
+	assert.Equal(t, orig, err)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/version"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+type StatusHandler struct {
+	apiProvider *provider.ApiProvider
+	logger      *zap.Logger
+	startedAt   time.Time
+}
+
+func NewStatusHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *StatusHandler {
+	return &StatusHandler{
+		apiProvider: apiProvider,
+		logger:      logger,
+		startedAt:   time.Now(),
+	}
+}
+
+// ServerStatusHandler reports version, credentials, registered tools, cache and
+// rate limiter state. It works while caches are still warming up.
+func (sh *StatusHandler) ServerStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sh.logger.Debug("ServerStatusHandler called", zap.Any("params", request.Params))
+
+	var parts []string
+	parts = append(parts, "# Slack MCP Server Status\n")
+
+	parts = append(parts, "## Version")
+	parts = append(parts, fmt.Sprintf("- version: %s", version.Version))
+	parts = append(parts, fmt.Sprintf("- commit: %s", version.CommitHash))
+	parts = append(parts, fmt.Sprintf("- build time: %s", version.BuildTime))
+	parts = append(parts, fmt.Sprintf("- transport: %s", sh.apiProvider.ServerTransport()))
+	parts = append(parts, fmt.Sprintf("- uptime: %s", time.Since(sh.startedAt).Round(time.Second)))
+	parts = append(parts, "")
+
+	parts = append(parts, "## Credentials")
+	parts = append(parts, fmt.Sprintf("- token type: %s", sh.apiProvider.TokenType()))
+	parts = append(parts, fmt.Sprintf("- bot token: %t", sh.apiProvider.IsBotToken()))
+	parts = append(parts, fmt.Sprintf("- oauth: %t", sh.apiProvider.IsOAuth()))
+	parts = append(parts, fmt.Sprintf("- enterprise: %t", sh.apiProvider.IsEnterprise()))
+	parts = append(parts, fmt.Sprintf("- file downloads: %t", sh.apiProvider.CanDownloadFiles()))
+	parts = append(parts, "")
+
+	ready, readyErr := sh.apiProvider.IsReady()
+	stats := sh.apiProvider.CacheStats()
+	parts = append(parts, "## Caches")
+	if ready {
+		parts = append(parts, "- ready: true")
+	} else {
+		parts = append(parts, fmt.Sprintf("- ready: false (%v)", readyErr))
+	}
+	parts = append(parts, fmt.Sprintf("- users: %d (%s)", stats.Users, cacheAge(stats.UsersUpdatedAt)))
+	parts = append(parts, fmt.Sprintf("- channels: %d (%s)", stats.Channels, cacheAge(stats.ChannelsUpdatedAt)))
+	parts = append(parts, "")
+
+	limiter := sh.apiProvider.RateLimiterState()
+	parts = append(parts, "## Rate limiter")
+	parts = append(parts, fmt.Sprintf("- rate: %.2f requests/s, burst %d", limiter.PerSecond, limiter.Burst))
+	parts = append(parts, fmt.Sprintf("- available tokens: %.2f", limiter.Tokens))
+	parts = append(parts, "")
+
+	parts = append(parts, "## Tools")
+	if srv := server.ServerFromContext(ctx); srv != nil {
+		tools := srv.ListTools()
+		names := make([]string, 0, len(tools))
+		for name := range tools {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			mode := "write"
+			if hint := tools[name].Tool.Annotations.ReadOnlyHint; hint != nil && *hint {
+				mode = "read-only"
+			}
+			parts = append(parts, fmt.Sprintf("- %s (%s)", name, mode))
+		}
+	} else {
+		parts = append(parts, "- (unavailable)")
+	}
+
+	return mcp.NewToolResultText(strings.Join(parts, "\n")), nil
+}
+
+func cacheAge(updatedAt time.Time) string {
+	if updatedAt.IsZero() {
+		return "not loaded"
+	}
+	return fmt.Sprintf("updated %s ago", time.Since(updatedAt).Round(time.Second))
+}
@@ -3,9 +3,9 @@ package handler
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/mark3labs/mcp-go/mcp"
 	"go.uber.org/zap"
@@ -119,10 +119,11 @@ func (tch *TeamContextHandler) GetTeamContextHandler(ctx context.Context, reques
 		return mcp.NewToolResultError("Slack workspace data is still loading. Please retry in a few seconds."), nil
 	}
 
-	// Read priority channels from env
-	priorityChannels := os.Getenv("SLACK_MCP_PRIORITY_CHANNELS")
-	priorityUsers := os.Getenv("SLACK_MCP_PRIORITY_USERS")
-	teamName := os.Getenv("SLACK_MCP_TEAM_NAME")
+	// Read priority channels from the config, which is reloaded when the file changes
+	team := config.Current().Team
+	priorityChannels := team.PriorityChannels
+	priorityUsers := team.PriorityUsers
+	teamName := team.Name
 	if teamName == "" {
 		teamName = "your team"
 	}
@@ -131,11 +132,11 @@ func (tch *TeamContextHandler) GetTeamContextHandler(ctx context.Context, reques
 	var contextParts []string
 	contextParts = append(contextParts, fmt.Sprintf("# Slack Workspace Context for %s\n", teamName))
 
-	if priorityChannels != "" {
+	if len(priorityChannels) > 0 {
 		contextParts = append(contextParts, "## Priority Channels")
 		contextParts = append(contextParts, "These are the main channels to focus on. Use the channel_id shown when calling Slack tools:\n")
 
-		channelEntries := strings.Split(priorityChannels, ",")
+		channelEntries := priorityChannels
 		channelsMap := tch.apiProvider.ProvideChannelsMaps()
 		if channelsMap == nil || channelsMap.Channels == nil {
 			return mcp.NewToolResultError("Channel cache not initialized"), nil
@@ -168,11 +169,11 @@ func (tch *TeamContextHandler) GetTeamContextHandler(ctx context.Context, reques
 		contextParts = append(contextParts, "")
 	}
 
-	if priorityUsers != "" {
+	if len(priorityUsers) > 0 {
 		contextParts = append(contextParts, "## Team Members")
 		contextParts = append(contextParts, "These are the key team members. Use the @username or DM channel_id when calling Slack tools:\n")
 
-		userEntries := strings.Split(priorityUsers, ",")
+		userEntries := priorityUsers
 		usersMap := tch.apiProvider.ProvideUsersMap()
 		channelsMap := tch.apiProvider.ProvideChannelsMaps()
 		if usersMap == nil || usersMap.Users == nil {
@@ -219,14 +220,14 @@ func (tch *TeamContextHandler) GetTeamContextHandler(ctx context.Context, reques
 		contextParts = append(contextParts, "")
 	}
 
-	if priorityChannels != "" || priorityUsers != "" {
+	if len(priorityChannels) > 0 || len(priorityUsers) > 0 {
 		contextParts = append(contextParts, "## Usage Guidelines")
 		contextParts = append(contextParts, "- **IMPORTANT**: When the user mentions a person or channel by nickname/alias (shown in bold above), use the corresponding @username or channel_id in tool calls")
 		contextParts = append(contextParts, "- For DMs with team members, use their dm_channel ID as channel_id in conversations_history")
 		contextParts = append(contextParts, "- For user filters in search, use @username format (e.g., filter_users_from: '@i.bastos')")
 		contextParts = append(contextParts, "- Bot messages are filtered by default. Use exclude_bots=false to include them.")
 	} else {
-		contextParts = append(contextParts, "No priority channels or team members configured. Set SLACK_MCP_PRIORITY_CHANNELS and SLACK_MCP_PRIORITY_USERS environment variables to provide context.")
+		contextParts = append(contextParts, "No priority channels or team members configured. Set SLACK_MCP_PRIORITY_CHANNELS and SLACK_MCP_PRIORITY_USERS environment variables (or team.priority_channels and team.priority_users in the config file) to provide context.")
 	}
 
 	return mcp.NewToolResultText(strings.Join(contextParts, "\n")), nil
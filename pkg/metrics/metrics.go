@@ -0,0 +1,144 @@
+// Package metrics holds the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "slack_mcp"
+
+// Registry is the registry served by Handler. A dedicated registry keeps the output
+// independent of whatever else registers with the global default.
+var Registry = prometheus.NewRegistry()
+
+var (
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "MCP tool calls by tool and outcome (success or error).",
+	}, []string{"tool", "outcome"})
+
+	ToolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "MCP tool call latency.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"tool"})
+
+	SlackRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_requests_total",
+		Help:      "HTTP requests to Slack by API method and status code.",
+	}, []string{"method", "code"})
+
+	SlackRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "slack_api_request_duration_seconds",
+		Help:      "Latency of HTTP requests to Slack by API method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	SlackRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_rate_limited_total",
+		Help:      "Responses from Slack with status 429 by API method.",
+	}, []string{"method"})
+
+	SlackRetryAfter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_retry_after_seconds_total",
+		Help:      "Sum of Retry-After delays requested by Slack by API method.",
+	}, []string{"method"})
+
+	RateLimiterWait = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limiter_wait_seconds_total",
+		Help:      "Time spent waiting on the client-side Slack rate limiter.",
+	})
+
+	ImageDownloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_downloads_total",
+		Help:      "Images considered for inline delivery by result (inlined, inlined_thumbnail, skipped_budget, skipped_size, failed).",
+	}, []string{"result"})
+
+	ImageDownloadBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_download_bytes_total",
+		Help:      "Bytes of image data downloaded from Slack.",
+	})
+
+	ImageCompressionRatio = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_compression_ratio",
+		Help:      "Compressed size divided by original size for converted images.",
+		Buckets:   []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.75, 1, 1.5},
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ToolCalls,
+		ToolDuration,
+		SlackRequests,
+		SlackRequestDuration,
+		SlackRateLimited,
+		SlackRetryAfter,
+		RateLimiterWait,
+		ImageDownloads,
+		ImageDownloadBytes,
+		ImageCompressionRatio,
+	)
+}
+
+// Handler serves the metrics in Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Register adds c to Registry, ignoring collectors that are already registered.
+func Register(c prometheus.Collector) error {
+	if err := Registry.Register(c); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveToolCall records one tool call.
+func ObserveToolCall(tool string, failed bool, d time.Duration) {
+	outcome := "success"
+	if failed {
+		outcome = "error"
+	}
+	ToolCalls.WithLabelValues(tool, outcome).Inc()
+	ToolDuration.WithLabelValues(tool).Observe(d.Seconds())
+}
+
+// SlackMethod maps a request URL to the Slack API method it calls, keeping label
+// cardinality bounded: "/api/chat.postMessage" becomes "chat.postMessage", file
+// downloads become "files.download" and anything else "other".
+func SlackMethod(host, path string) string {
+	if i := strings.Index(path, "/api/"); i >= 0 {
+		method := path[i+len("/api/"):]
+		if j := strings.IndexAny(method, "/?"); j >= 0 {
+			method = method[:j]
+		}
+		if method != "" {
+			return method
+		}
+	}
+	if strings.HasPrefix(host, "files.") || strings.HasPrefix(path, "/files-pri/") {
+		return "files.download"
+	}
+	return "other"
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSlackMethod(t *testing.T) {
+	tests := []struct {
+		host, path string
+		want       string
+	}{
+		{"slack.com", "/api/chat.postMessage", "chat.postMessage"},
+		{"acme.slack.com", "/api/conversations.history", "conversations.history"},
+		{"acme.enterprise.slack.com", "/api/client.userBoot/", "client.userBoot"},
+		{"files.slack.com", "/files-pri/T1-F1/image.png", "files.download"},
+		{"acme.slack.com", "/files-pri/T1-F1/image.png", "files.download"},
+		{"example.com", "/", "other"},
+		{"slack.com", "/api/", "other"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SlackMethod(tt.host, tt.path), tt.host+tt.path)
+	}
+}
+
+func TestUnitRoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "conversations.replies") {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewRoundTripper(http.DefaultTransport)}
+
+	limitedBefore := testutil.ToFloat64(SlackRateLimited.WithLabelValues("conversations.replies"))
+	waitBefore := testutil.ToFloat64(SlackRetryAfter.WithLabelValues("conversations.replies"))
+	okBefore := testutil.ToFloat64(SlackRequests.WithLabelValues("users.list", "200"))
+
+	for _, method := range []string{"users.list", "conversations.replies"} {
+		resp, err := client.Get(srv.URL + "/api/" + method)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, okBefore+1, testutil.ToFloat64(SlackRequests.WithLabelValues("users.list", "200")))
+	assert.Equal(t, limitedBefore+1, testutil.ToFloat64(SlackRateLimited.WithLabelValues("conversations.replies")))
+	assert.Equal(t, waitBefore+7, testutil.ToFloat64(SlackRetryAfter.WithLabelValues("conversations.replies")))
+}
+
+func TestUnitHandler(t *testing.T) {
+	ObserveToolCall("channels_list", false, 120*time.Millisecond)
+	ObserveToolCall("channels_list", true, time.Second)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `slack_mcp_tool_calls_total{outcome="error",tool="channels_list"}`)
+	assert.Contains(t, body, `slack_mcp_tool_call_duration_seconds_bucket{tool="channels_list"`)
+	assert.Contains(t, body, "go_goroutines")
+}
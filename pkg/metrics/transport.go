@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// roundTripper records Slack API metrics for every HTTP request.
+type roundTripper struct {
+	next http.RoundTripper
+}
+
+// NewRoundTripper wraps next with Slack API request metrics.
+func NewRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &roundTripper{next: next}
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := SlackMethod(req.URL.Host, req.URL.Path)
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	SlackRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		SlackRequests.WithLabelValues(method, "error").Inc()
+		return resp, err
+	}
+
+	SlackRequests.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		SlackRateLimited.WithLabelValues(method).Inc()
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			SlackRetryAfter.WithLabelValues(method).Add(float64(secs))
+		}
+	}
+	return resp, nil
+}
@@ -10,8 +10,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
 	"github.com/korotovsky/slack-mcp-server/pkg/transport"
 	"github.com/rusq/slackdump/v3/auth"
@@ -22,6 +26,10 @@ import (
 
 const usersNotReadyMsg = "users cache is not ready yet, sync process is still running... please wait"
 const channelsNotReadyMsg = "channels cache is not ready yet, sync process is still running... please wait"
+
+// userGroupsTTL is how long the user group handles resolved in outgoing mentions are kept.
+const userGroupsTTL = 10 * time.Minute
+
 const defaultUA = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36"
 
 var AllChanTypes = []string{"mpim", "im", "public_channel", "private_channel"}
@@ -77,6 +85,7 @@ type SlackAPI interface {
 	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
 	GetUsersInfo(users ...string) (*[]slack.User, error)
 	PostMessageContext(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error)
+	OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error)
 	MarkConversationContext(ctx context.Context, channel, ts string) error
 
 	// Used to get messages
@@ -84,6 +93,9 @@ type SlackAPI interface {
 	GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
 	SearchContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, *slack.SearchFiles, error)
 
+	// Used to resolve user group mentions
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+
 	// Used to get channels list from both Slack and Enterprise Grid versions
 	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
 
@@ -95,6 +107,9 @@ type SlackAPI interface {
 
 	// File info methods
 	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+
+	// File upload methods
+	UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error)
 }
 
 type MCPSlackClient struct {
@@ -104,11 +119,15 @@ type MCPSlackClient struct {
 
 	authResponse *slack.AuthTestResponse
 	authProvider auth.Provider
+	logger       *zap.Logger
 
 	isEnterprise bool
 	isOAuth      bool
 	isBotToken   bool
 	teamEndpoint string
+
+	// grantedScopes is nil when the scopes are unknown, see ApiProvider.GrantedScopes.
+	grantedScopes []string
 }
 
 type ApiProvider struct {
@@ -118,15 +137,32 @@ type ApiProvider struct {
 
 	rateLimiter *rate.Limiter
 
-	users      map[string]slack.User
-	usersInv   map[string]string
-	usersCache string
-	usersReady bool
+	users          map[string]slack.User
+	usersInv       map[string]string
+	usersCache     string
+	usersReady     bool
+	usersUpdatedAt time.Time
+
+	channels          map[string]Channel
+	channelsInv       map[string]string
+	channelsCache     string
+	channelsReady     bool
+	channelsUpdatedAt time.Time
+	// channelsMu guards the channels maps, which are replaced rather than updated
+	// because ProvideChannelsMaps hands them out to readers.
+	channelsMu sync.RWMutex
+
+	userGroupsMu        sync.Mutex
+	userGroupsInv       map[string]string
+	userGroupsFetchedAt time.Time
+}
 
-	channels      map[string]Channel
-	channelsInv   map[string]string
-	channelsCache string
-	channelsReady bool
+// CacheStats describes the in-memory users and channels caches.
+type CacheStats struct {
+	Users             int
+	Channels          int
+	UsersUpdatedAt    time.Time
+	ChannelsUpdatedAt time.Time
 }
 
 func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlackClient, error) {
@@ -172,21 +208,40 @@ func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlac
 	isOAuth := strings.HasPrefix(token, "xoxp-") || strings.HasPrefix(token, "xoxb-")
 	isBotToken := strings.HasPrefix(token, "xoxb-")
 
+	var grantedScopes []string
+	if isOAuth {
+		grantedScopes, err = fetchGrantedScopes(context.Background(), httpClient, authResp.URL+"api/", token)
+		if err != nil {
+			logger.Warn("Failed to read granted OAuth scopes, assuming all tools are available",
+				zap.String("context", "console"),
+				zap.Error(err),
+			)
+		} else {
+			logger.Info("Granted OAuth scopes",
+				zap.String("context", "console"),
+				zap.Strings("scopes", grantedScopes),
+			)
+		}
+	}
+
 	return &MCPSlackClient{
 		slackClient:  slackClient,
 		edgeClient:   edgeClient,
 		httpClient:   httpClient,
 		authResponse: authResponse,
 		authProvider: authProvider,
+		logger:       logger,
 		isEnterprise: isEnterprise,
 		isOAuth:      isOAuth,
 		isBotToken:   isBotToken,
 		teamEndpoint: authResp.URL,
+
+		grantedScopes: grantedScopes,
 	}, nil
 }
 
 func (c *MCPSlackClient) AuthTest() (*slack.AuthTestResponse, error) {
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+	if config.Current().Slack.IsDemo() {
 		return &slack.AuthTestResponse{
 			URL:          "https://_.slack.com",
 			Team:         "Demo Team",
@@ -290,6 +345,18 @@ func (c *MCPSlackClient) SearchContext(ctx context.Context, query string, params
 	return c.slackClient.SearchContext(ctx, query, params)
 }
 
+func (c *MCPSlackClient) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return c.slackClient.GetUserGroupsContext(ctx, options...)
+}
+
+func (c *MCPSlackClient) OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	return c.slackClient.OpenConversationContext(ctx, params)
+}
+
+func (c *MCPSlackClient) UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+	return c.slackClient.UploadFileV2Context(ctx, params)
+}
+
 func (c *MCPSlackClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
 	return c.slackClient.PostMessageContext(ctx, channelID, options...)
 }
@@ -299,8 +366,9 @@ func (c *MCPSlackClient) ClientUserBoot(ctx context.Context) (*edge.ClientUserBo
 }
 
 func (c *MCPSlackClient) GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error {
-	// Use the slack-go client which has the httpClient with cookie jar configured
-	// The cookie jar ensures cookies are properly sent to files.slack.com
+	if !c.isOAuth {
+		return c.downloadWithCookies(ctx, downloadURL, writer)
+	}
 	return c.slackClient.GetFileContext(ctx, downloadURL, writer)
 }
 
@@ -321,10 +389,10 @@ func (c *MCPSlackClient) IsBotToken() bool {
 }
 
 // CanDownloadFiles returns true if file downloads are supported with the current auth method.
-// Browser session tokens (xoxc/xoxd) cannot reliably download files from files.slack.com
-// because they require browser cookies that may not be properly forwarded.
+// OAuth tokens download with a bearer token, browser session tokens (xoxc/xoxd) with the
+// d cookie.
 func (c *MCPSlackClient) CanDownloadFiles() bool {
-	return c.isOAuth
+	return c.isOAuth || len(c.authProvider.Cookies()) > 0
 }
 
 func (c *MCPSlackClient) Raw() struct {
@@ -346,11 +414,11 @@ func New(transport string, logger *zap.Logger) *ApiProvider {
 		err          error
 	)
 
-	// Read all environment variables
-	xoxpToken := os.Getenv("SLACK_MCP_XOXP_TOKEN")
-	xoxbToken := os.Getenv("SLACK_MCP_XOXB_TOKEN")
-	xoxcToken := os.Getenv("SLACK_MCP_XOXC_TOKEN")
-	xoxdToken := os.Getenv("SLACK_MCP_XOXD_TOKEN")
+	creds := config.Current().Slack
+	xoxpToken := creds.XoxpToken
+	xoxbToken := creds.XoxbToken
+	xoxcToken := creds.XoxcToken
+	xoxdToken := creds.XoxdToken
 
 	// Warn if both user and bot tokens are set
 	if xoxpToken != "" && xoxbToken != "" {
@@ -406,19 +474,19 @@ func newWithXOXP(transport string, authProvider auth.ValueAuth, logger *zap.Logg
 		err    error
 	)
 
-	usersCache := os.Getenv("SLACK_MCP_USERS_CACHE")
+	usersCache := config.Current().Slack.UsersCache
 	if usersCache == "" {
 		cacheDir := getCacheDir()
 		usersCache = filepath.Join(cacheDir, "users_cache.json")
 	}
 
-	channelsCache := os.Getenv("SLACK_MCP_CHANNELS_CACHE")
+	channelsCache := config.Current().Slack.ChannelsCache
 	if channelsCache == "" {
 		cacheDir := getCacheDir()
 		channelsCache = filepath.Join(cacheDir, "channels_cache_v2.json")
 	}
 
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+	if config.Current().Slack.IsDemo() {
 		logger.Info("Demo credentials are set, skip.")
 	} else {
 		client, err = NewMCPSlackClient(authProvider, logger)
@@ -456,19 +524,19 @@ func newWithXOXC(transport string, authProvider auth.ValueAuth, logger *zap.Logg
 		err    error
 	)
 
-	usersCache := os.Getenv("SLACK_MCP_USERS_CACHE")
+	usersCache := config.Current().Slack.UsersCache
 	if usersCache == "" {
 		cacheDir := getCacheDir()
 		usersCache = filepath.Join(cacheDir, "users_cache.json")
 	}
 
-	channelsCache := os.Getenv("SLACK_MCP_CHANNELS_CACHE")
+	channelsCache := config.Current().Slack.ChannelsCache
 	if channelsCache == "" {
 		cacheDir := getCacheDir()
 		channelsCache = filepath.Join(cacheDir, "channels_cache_v2.json")
 	}
 
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+	if config.Current().Slack.IsDemo() {
 		logger.Info("Demo credentials are set, skip.")
 	} else {
 		client, err = NewMCPSlackClient(authProvider, logger)
@@ -515,6 +583,7 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 			ap.logger.Info("Loaded users from cache",
 				zap.Int("count", len(cachedUsers)),
 				zap.String("cache_file", ap.usersCache))
+			ap.usersUpdatedAt = cacheFileModTime(ap.usersCache)
 			ap.usersReady = true
 			return nil
 		}
@@ -553,7 +622,7 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 	if data, err := json.MarshalIndent(list, "", "  "); err != nil {
 		ap.logger.Error("Failed to marshal users for cache", zap.Error(err))
 	} else {
-		if err := ioutil.WriteFile(ap.usersCache, data, 0644); err != nil {
+		if err := writeCacheFile(ap.usersCache, data); err != nil {
 			ap.logger.Error("Failed to write cache file",
 				zap.String("cache_file", ap.usersCache),
 				zap.Error(err))
@@ -564,6 +633,7 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 		}
 	}
 
+	ap.usersUpdatedAt = time.Now()
 	ap.usersReady = true
 
 	return nil
@@ -579,37 +649,37 @@ func (ap *ApiProvider) RefreshChannels(ctx context.Context) error {
 		} else {
 			// Re-map channels with current users cache to ensure DM names are populated
 			usersMap := ap.ProvideUsersMap().Users
-			for _, c := range cachedChannels {
+			for i, c := range cachedChannels {
 				// For IM channels, re-generate the name and purpose using current users cache
 				if c.IsIM {
 					// Re-map the channel to get updated user name if available
-					remappedChannel := mapChannel(
+					cachedChannels[i] = mapChannel(
 						c.ID, "", "", c.Topic, c.Purpose,
 						c.User, c.Members, c.MemberCount,
 						c.IsIM, c.IsMpIM, c.IsPrivate,
 						usersMap,
 					)
-					ap.channels[c.ID] = remappedChannel
-					ap.channelsInv[remappedChannel.Name] = c.ID
-				} else {
-					ap.channels[c.ID] = c
-					ap.channelsInv[c.Name] = c.ID
 				}
 			}
+			ap.addChannels(cachedChannels...)
 			ap.logger.Info("Loaded channels from cache and re-mapped DM names",
 				zap.Int("count", len(cachedChannels)),
 				zap.String("cache_file", ap.channelsCache))
-			ap.channelsReady = true
+			ap.channelsLoaded(cacheFileModTime(ap.channelsCache))
 			return nil
 		}
 	}
 
 	channels := ap.GetChannels(ctx, AllChanTypes)
+	if err := ctx.Err(); err != nil {
+		// A partial listing must not overwrite the cache file.
+		return err
+	}
 
 	if data, err := json.MarshalIndent(channels, "", "  "); err != nil {
 		ap.logger.Error("Failed to marshal channels for cache", zap.Error(err))
 	} else {
-		if err := ioutil.WriteFile(ap.channelsCache, data, 0644); err != nil {
+		if err := writeCacheFile(ap.channelsCache, data); err != nil {
 			ap.logger.Error("Failed to write cache file",
 				zap.String("cache_file", ap.channelsCache),
 				zap.Error(err))
@@ -620,7 +690,7 @@ func (ap *ApiProvider) RefreshChannels(ctx context.Context) error {
 		}
 	}
 
-	ap.channelsReady = true
+	ap.channelsLoaded(time.Now())
 
 	return nil
 }
@@ -676,10 +746,12 @@ func (ap *ApiProvider) GetChannelsType(ctx context.Context, channelType string)
 	)
 
 	for {
+		waitStart := time.Now()
 		if err := ap.rateLimiter.Wait(ctx); err != nil {
 			ap.logger.Error("Rate limiter wait failed", zap.Error(err))
 			return nil
 		}
+		metrics.RateLimiterWait.Add(time.Since(waitStart).Seconds())
 
 		channels, nextcur, err = ap.client.GetConversationsContext(ctx, params)
 		ap.logger.Debug("Fetched channels for ",
@@ -729,14 +801,11 @@ func (ap *ApiProvider) GetChannels(ctx context.Context, channelTypes []string) [
 		chans = append(chans, typeChannels...)
 	}
 
-	for _, ch := range chans {
-		ap.channels[ch.ID] = ch
-		ap.channelsInv[ch.Name] = ch.ID
-	}
+	all := ap.addChannels(chans...)
 
 	var res []Channel
 	for _, t := range channelTypes {
-		for _, channel := range ap.channels {
+		for _, channel := range all {
 			if t == "public_channel" && !channel.IsPrivate {
 				res = append(res, channel)
 			}
@@ -755,6 +824,61 @@ func (ap *ApiProvider) GetChannels(ctx context.Context, channelTypes []string) [
 	return res
 }
 
+// OpenConversation opens a DM with one user, or a group DM with several, using
+// conversations.open, and adds it to the channels cache. Slack returns the existing
+// conversation if there already is one.
+func (ap *ApiProvider) OpenConversation(ctx context.Context, userIDs []string) (Channel, error) {
+	resp, _, _, err := ap.client.OpenConversationContext(ctx, &slack.OpenConversationParameters{
+		Users:    userIDs,
+		ReturnIM: true,
+	})
+	if err != nil {
+		return Channel{}, err
+	}
+
+	isIM := len(userIDs) == 1
+	user, members := resp.User, resp.Members
+	if isIM && user == "" {
+		user = userIDs[0]
+	}
+	if len(members) == 0 {
+		members = userIDs
+	}
+	name := resp.NameNormalized
+	if name == "" {
+		name = resp.Name
+	}
+	if name == "" {
+		name = resp.ID
+	}
+	ch := mapChannel(resp.ID, resp.Name, name, "", "", user, members, len(members), isIM, !isIM, true, ap.ProvideUsersMap().Users)
+
+	// The lock is held while the cache file is written, so concurrent opens cannot
+	// replace it with an older list.
+	ap.channelsMu.Lock()
+	defer ap.channelsMu.Unlock()
+	channels := ap.addChannelsLocked(ch)
+
+	list := make([]Channel, 0, len(channels))
+	for _, c := range channels {
+		list = append(list, c)
+	}
+	if data, err := json.MarshalIndent(list, "", "  "); err != nil {
+		ap.logger.Error("Failed to marshal channels for cache", zap.Error(err))
+	} else if err := writeCacheFile(ap.channelsCache, data); err != nil {
+		ap.logger.Error("Failed to write cache file",
+			zap.String("cache_file", ap.channelsCache),
+			zap.Error(err))
+	}
+
+	ap.logger.Info("Opened conversation",
+		zap.String("channel", ch.ID),
+		zap.String("name", ch.Name),
+		zap.Strings("users", userIDs),
+	)
+	return ch, nil
+}
+
 func (ap *ApiProvider) ProvideUsersMap() *UsersCache {
 	return &UsersCache{
 		Users:    ap.users,
@@ -762,23 +886,138 @@ func (ap *ApiProvider) ProvideUsersMap() *UsersCache {
 	}
 }
 
+// ProvideChannelsMaps returns the channels maps. They must not be modified: updates
+// replace them, so readers keep a consistent snapshot.
 func (ap *ApiProvider) ProvideChannelsMaps() *ChannelsCache {
+	ap.channelsMu.RLock()
+	defer ap.channelsMu.RUnlock()
 	return &ChannelsCache{
 		Channels:    ap.channels,
 		ChannelsInv: ap.channelsInv,
 	}
 }
 
+// addChannels adds channels to the channels maps and returns the new channels map.
+func (ap *ApiProvider) addChannels(channels ...Channel) map[string]Channel {
+	ap.channelsMu.Lock()
+	defer ap.channelsMu.Unlock()
+	return ap.addChannelsLocked(channels...)
+}
+
+// addChannelsLocked is addChannels for callers holding channelsMu. The maps are copied
+// rather than updated in place, as readers use them without holding the lock.
+func (ap *ApiProvider) addChannelsLocked(add ...Channel) map[string]Channel {
+	channels := make(map[string]Channel, len(ap.channels)+len(add))
+	for id, c := range ap.channels {
+		channels[id] = c
+	}
+	channelsInv := make(map[string]string, len(ap.channelsInv)+len(add))
+	for name, id := range ap.channelsInv {
+		channelsInv[name] = id
+	}
+	for _, c := range add {
+		channels[c.ID] = c
+		channelsInv[c.Name] = c.ID
+	}
+	ap.channels, ap.channelsInv = channels, channelsInv
+	return channels
+}
+
+// channelsLoaded marks the channels cache as ready.
+func (ap *ApiProvider) channelsLoaded(updatedAt time.Time) {
+	ap.channelsMu.Lock()
+	defer ap.channelsMu.Unlock()
+	ap.channelsUpdatedAt = updatedAt
+	ap.channelsReady = true
+}
+
+// ProvideUserGroupsInv returns user group handles mapped to their IDs. The list is
+// fetched from usergroups.list at most every userGroupsTTL; it is empty when the token
+// cannot list user groups, e.g. without the usergroups:read scope.
+func (ap *ApiProvider) ProvideUserGroupsInv(ctx context.Context) map[string]string {
+	ap.userGroupsMu.Lock()
+	defer ap.userGroupsMu.Unlock()
+
+	if !ap.userGroupsFetchedAt.IsZero() && time.Since(ap.userGroupsFetchedAt) < userGroupsTTL {
+		return ap.userGroupsInv
+	}
+	ap.userGroupsFetchedAt = time.Now()
+	if config.Current().Slack.IsDemo() {
+		return ap.userGroupsInv
+	}
+
+	groups, err := ap.client.GetUserGroupsContext(ctx)
+	if err != nil {
+		ap.logger.Warn("Failed to list user groups, user group mentions will not be resolved", zap.Error(err))
+		return ap.userGroupsInv
+	}
+	inv := make(map[string]string, len(groups))
+	for _, g := range groups {
+		if g.Handle != "" && g.DateDelete.Time().IsZero() {
+			inv[g.Handle] = g.ID
+		}
+	}
+	ap.userGroupsInv = inv
+	return inv
+}
+
 func (ap *ApiProvider) IsReady() (bool, error) {
 	if !ap.usersReady {
 		return false, ErrUsersNotReady
 	}
+	ap.channelsMu.RLock()
+	defer ap.channelsMu.RUnlock()
 	if !ap.channelsReady {
 		return false, ErrChannelsNotReady
 	}
 	return true, nil
 }
 
+func (ap *ApiProvider) CacheStats() CacheStats {
+	ap.channelsMu.RLock()
+	defer ap.channelsMu.RUnlock()
+	return CacheStats{
+		Users:             len(ap.users),
+		Channels:          len(ap.channels),
+		UsersUpdatedAt:    ap.usersUpdatedAt,
+		ChannelsUpdatedAt: ap.channelsUpdatedAt,
+	}
+}
+
+// writeCacheFile replaces path atomically, so a process killed mid-write leaves the
+// previous cache intact instead of a truncated file.
+func writeCacheFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func cacheFileModTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Now()
+	}
+	return fi.ModTime()
+}
+
 func (ap *ApiProvider) ServerTransport() string {
 	return ap.transport
 }
@@ -798,6 +1037,58 @@ func (ap *ApiProvider) CanDownloadFiles() bool {
 	return ok && client != nil && client.CanDownloadFiles()
 }
 
+// IsOAuth returns true for official OAuth tokens (xoxp or xoxb).
+func (ap *ApiProvider) IsOAuth() bool {
+	client, ok := ap.client.(*MCPSlackClient)
+	return ok && client != nil && client.isOAuth
+}
+
+func (ap *ApiProvider) IsEnterprise() bool {
+	client, ok := ap.client.(*MCPSlackClient)
+	return ok && client != nil && client.IsEnterprise()
+}
+
+// TokenType describes the configured credentials: "bot", "user", "browser" or "demo".
+func (ap *ApiProvider) TokenType() string {
+	client, ok := ap.client.(*MCPSlackClient)
+	switch {
+	case !ok || client == nil:
+		return "demo"
+	case client.isBotToken:
+		return "bot"
+	case client.isOAuth:
+		return "user"
+	default:
+		return "browser"
+	}
+}
+
+// CheckAuth verifies the credentials are still accepted by Slack. It is a no-op with
+// demo credentials.
+func (ap *ApiProvider) CheckAuth(ctx context.Context) error {
+	client, ok := ap.client.(*MCPSlackClient)
+	if !ok || client == nil {
+		return nil
+	}
+	_, err := client.AuthTestContext(ctx)
+	return err
+}
+
+// RateLimiterState is a snapshot of the client-side limiter used for channel listing.
+type RateLimiterState struct {
+	PerSecond float64
+	Burst     int
+	Tokens    float64
+}
+
+func (ap *ApiProvider) RateLimiterState() RateLimiterState {
+	return RateLimiterState{
+		PerSecond: float64(ap.rateLimiter.Limit()),
+		Burst:     ap.rateLimiter.Burst(),
+		Tokens:    ap.rateLimiter.Tokens(),
+	}
+}
+
 func mapChannel(
 	id, name, nameNormalized, topic, purpose, user string,
 	members []string,
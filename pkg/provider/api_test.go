@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitAddChannelsKeepsSnapshots(t *testing.T) {
+	ap := &ApiProvider{channels: map[string]Channel{}, channelsInv: map[string]string{}}
+	ap.addChannels(Channel{ID: "C1", Name: "#general"})
+	snapshot := ap.ProvideChannelsMaps()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			ap.addChannels(Channel{ID: fmt.Sprintf("C%d0", i), Name: fmt.Sprintf("#c%d", i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			for id, c := range ap.ProvideChannelsMaps().Channels {
+				_ = ap.ProvideChannelsMaps().ChannelsInv[c.Name] == id
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, snapshot.Channels, 1, "handed out maps are never modified")
+	maps := ap.ProvideChannelsMaps()
+	assert.Len(t, maps.Channels, 9)
+	assert.Equal(t, "C30", maps.ChannelsInv["#c3"])
+	assert.Equal(t, 9, ap.CacheStats().Channels)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrFileSessionRejected is returned when files.slack.com answers a browser session
+// download with a sign-in page instead of the file.
+var ErrFileSessionRejected = errors.New("slack rejected the browser session for the file download")
+
+// maxDownloadRedirects bounds the redirects followed by a browser session download.
+const maxDownloadRedirects = 5
+
+// isSlackHost reports whether host is slack.com or one of its subdomains, the only
+// hosts the browser session cookies are sent to.
+func isSlackHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "slack.com" || strings.HasSuffix(host, ".slack.com")
+}
+
+// downloadWithCookies downloads a private file with the browser session: the d cookie
+// from the shared cookie jar authenticates the request, as in the Slack web client.
+// When Slack redirects to a sign-in page the token is re-validated with auth.test to
+// tell an expired session from a cookie that does not belong to the token.
+func (c *MCPSlackClient) downloadWithCookies(ctx context.Context, downloadURL string, writer io.Writer) error {
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return fmt.Errorf("invalid download URL: %w", err)
+	}
+	if u.Scheme != "https" || !isSlackHost(u.Hostname()) {
+		return fmt.Errorf("refusing to send the browser session to %s, only https://*.slack.com URLs can be downloaded", u.Host)
+	}
+
+	client := *c.httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxDownloadRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxDownloadRedirects)
+		}
+		if req.URL.Scheme != "https" || !isSlackHost(req.URL.Hostname()) {
+			return fmt.Errorf("refusing to follow a redirect to %s", req.URL.Host)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return c.sessionRejected(ctx, fmt.Sprintf("status %d", resp.StatusCode))
+		}
+		return fmt.Errorf("file download failed with status %d", resp.StatusCode)
+	}
+	// A sign-in page is served after a redirect away from the file; an HTML file that
+	// was actually shared is served from its own URL.
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/html" && resp.Request.URL.Path != u.Path {
+		return c.sessionRejected(ctx, "redirected to "+resp.Request.URL.Host+resp.Request.URL.Path)
+	}
+
+	_, err = io.Copy(writer, resp.Body)
+	return err
+}
+
+// sessionRejected builds the error for a download the browser session was not accepted
+// for, re-validating the token to tell the user which credential to refresh.
+func (c *MCPSlackClient) sessionRejected(ctx context.Context, reason string) error {
+	if _, err := c.slackClient.AuthTestContext(ctx); err != nil {
+		c.logger.Warn("Browser session is no longer valid", zap.String("reason", reason), zap.Error(err))
+		return fmt.Errorf("%w (%s): the session token is no longer valid (%v), refresh SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN", ErrFileSessionRejected, reason, err)
+	}
+	c.logger.Warn("Browser session cookie rejected by files.slack.com", zap.String("reason", reason))
+	return fmt.Errorf("%w (%s): the session token is valid, check that SLACK_MCP_XOXD_TOKEN is the d cookie of the same browser session", ErrFileSessionRejected, reason)
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newDownloadTestClient returns a client whose requests to any host reach srv, which
+// also answers auth.test with validToken.
+func newDownloadTestClient(t *testing.T, mux *http.ServeMux, validToken *bool) *MCPSlackClient {
+	t.Helper()
+	mux.HandleFunc("/api/auth.test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if *validToken {
+			_, _ = w.Write([]byte(`{"ok":true,"user_id":"U1","team_id":"T1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	transport := srv.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	// The test certificate is issued for example.com.
+	transport.TLSClientConfig = &tls.Config{RootCAs: transport.TLSClientConfig.RootCAs, ServerName: "example.com"}
+	httpClient := &http.Client{Transport: transport}
+
+	return &MCPSlackClient{
+		slackClient: slack.New("xoxc-test", slack.OptionHTTPClient(httpClient), slack.OptionAPIURL("https://slack.com/api/")),
+		httpClient:  httpClient,
+		logger:      zap.NewNop(),
+	}
+}
+
+func TestUnitIsSlackHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"slack.com", true},
+		{"files.slack.com", true},
+		{"Files.Slack.COM", true},
+		{"acme.enterprise.slack.com", true},
+		{"slack.com.evil.io", false},
+		{"evilslack.com", false},
+		{"slack-edge.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isSlackHost(tt.host), tt.host)
+	}
+}
+
+func TestUnitDownloadWithCookies(t *testing.T) {
+	validToken := true
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files-pri/T1-F1/report.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://files.slack.com/files-pri/T1-F1/download/report.txt", http.StatusFound)
+	})
+	mux.HandleFunc("/files-pri/T1-F1/download/report.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("quarterly numbers"))
+	})
+	mux.HandleFunc("/files-pri/T1-F2/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html>shared page</html>"))
+	})
+	mux.HandleFunc("/files-pri/T1-F3/expired.pdf", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://acme.slack.com/?redir=%2Ffiles-pri%2FT1-F3", http.StatusFound)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>sign in</html>"))
+	})
+	mux.HandleFunc("/files-pri/T1-F4/offsite.png", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://cdn.example.com/offsite.png", http.StatusFound)
+	})
+	mux.HandleFunc("/files-pri/T1-F5/loop.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	})
+	mux.HandleFunc("/files-pri/T1-F6/unauthorized.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/files-pri/T1-F7/forbidden.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/files-pri/T1-F8/missing.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	c := newDownloadTestClient(t, mux, &validToken)
+
+	download := func(url string) (string, error) {
+		var buf bytes.Buffer
+		err := c.downloadWithCookies(context.Background(), url, &buf)
+		return buf.String(), err
+	}
+
+	t.Run("file after a Slack redirect", func(t *testing.T) {
+		body, err := download("https://files.slack.com/files-pri/T1-F1/report.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "quarterly numbers", body)
+	})
+
+	t.Run("HTML file served at its own URL", func(t *testing.T) {
+		body, err := download("https://files.slack.com/files-pri/T1-F2/page.html")
+		require.NoError(t, err)
+		assert.Equal(t, "<html>shared page</html>", body)
+	})
+
+	t.Run("redirect to a sign-in page", func(t *testing.T) {
+		validToken = true
+		_, err := download("https://files.slack.com/files-pri/T1-F3/expired.pdf")
+		assert.ErrorIs(t, err, ErrFileSessionRejected)
+		assert.ErrorContains(t, err, "redirected to acme.slack.com/")
+		assert.ErrorContains(t, err, "d cookie of the same browser session")
+
+		validToken = false
+		_, err = download("https://files.slack.com/files-pri/T1-F3/expired.pdf")
+		assert.ErrorIs(t, err, ErrFileSessionRejected)
+		assert.ErrorContains(t, err, "no longer valid")
+	})
+
+	t.Run("rejected status", func(t *testing.T) {
+		for _, path := range []string{"/files-pri/T1-F6/unauthorized.txt", "/files-pri/T1-F7/forbidden.txt"} {
+			validToken = true
+			_, err := download("https://files.slack.com" + path)
+			assert.ErrorIs(t, err, ErrFileSessionRejected, path)
+			assert.ErrorContains(t, err, "session token is valid", path)
+
+			validToken = false
+			_, err = download("https://files.slack.com" + path)
+			assert.ErrorIs(t, err, ErrFileSessionRejected, path)
+			assert.ErrorContains(t, err, "refresh SLACK_MCP_XOXC_TOKEN", path)
+		}
+
+		_, err := download("https://files.slack.com/files-pri/T1-F8/missing.txt")
+		assert.NotErrorIs(t, err, ErrFileSessionRejected)
+		assert.ErrorContains(t, err, "status 404")
+	})
+
+	t.Run("redirect off Slack", func(t *testing.T) {
+		_, err := download("https://files.slack.com/files-pri/T1-F4/offsite.png")
+		assert.ErrorContains(t, err, "refusing to follow a redirect to cdn.example.com")
+	})
+
+	t.Run("redirect cap", func(t *testing.T) {
+		_, err := download("https://files.slack.com/files-pri/T1-F5/loop.txt")
+		assert.ErrorContains(t, err, "stopped after 5 redirects")
+	})
+
+	t.Run("URLs outside Slack", func(t *testing.T) {
+		for _, url := range []string{"http://files.slack.com/files-pri/T1-F1/report.txt", "https://files.example.com/x", "https://slack.com.evil.io/x"} {
+			_, err := download(url)
+			assert.ErrorContains(t, err, "refusing to send the browser session", url)
+		}
+	})
+}
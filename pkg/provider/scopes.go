@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// fetchGrantedScopes calls auth.test directly to read the X-OAuth-Scopes header, which
+// slack-go does not expose. apiURL is the workspace API base, e.g.
+// "https://acme.slack.com/api/".
+func fetchGrantedScopes(ctx context.Context, client *http.Client, apiURL, token string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"auth.test", strings.NewReader(url.Values{}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth.test returned status %d", resp.StatusCode)
+	}
+
+	header := resp.Header.Get("X-OAuth-Scopes")
+	if header == "" {
+		return nil, fmt.Errorf("auth.test response has no X-OAuth-Scopes header")
+	}
+	return parseScopes(header), nil
+}
+
+func parseScopes(header string) []string {
+	var scopes []string
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// GrantedScopes returns the OAuth scopes of the configured token. ok is false when they
+// are unknown: browser session tokens are not scope limited, and demo credentials or a
+// failed lookup leave nothing to go on, so callers should assume everything is allowed.
+func (ap *ApiProvider) GrantedScopes() (scopes []string, ok bool) {
+	client, isClient := ap.client.(*MCPSlackClient)
+	if !isClient || client == nil || client.grantedScopes == nil {
+		return nil, false
+	}
+	return client.grantedScopes, true
+}
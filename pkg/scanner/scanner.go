@@ -0,0 +1,205 @@
+// Package scanner finds secrets and personal data in text before it leaves the server.
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Finding is a match of one detector in the scanned text, as byte offsets.
+type Finding struct {
+	Kind  string
+	Start int
+	End   int
+}
+
+type detector struct {
+	kind    string
+	re      *regexp.Regexp
+	isValid func(match string) bool
+}
+
+// builtin detectors, ordered by how specific they are: when two findings overlap the
+// earlier one wins.
+var builtin = []detector{
+	{kind: "private key", re: regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?(?:-----END [A-Z ]*PRIVATE KEY-----|\z)`)},
+	{kind: "Slack token", re: regexp.MustCompile(`\bxox[abcdeoprs]-[0-9A-Za-z-]{10,}`)},
+	{kind: "Slack token", re: regexp.MustCompile(`\bxapp-\d-[0-9A-Za-z-]{10,}`)},
+	{kind: "Slack webhook", re: regexp.MustCompile(`https://hooks\.slack\.com/(?:services|workflows)/[0-9A-Za-z/_-]+`)},
+	{kind: "AWS access key", re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[0-9A-Z]{16}\b`)},
+	{kind: "AWS secret key", re: regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private).{0,20}?[:=]\s*["']?[0-9A-Za-z/+]{40}\b`)},
+	{kind: "GCP API key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{kind: "GCP service account key", re: regexp.MustCompile(`"private_key_id"\s*:\s*"[0-9a-f]{40}"`)},
+	{kind: "JWT", re: regexp.MustCompile(`\beyJ[0-9A-Za-z_-]{5,}\.eyJ[0-9A-Za-z_-]{5,}\.[0-9A-Za-z_-]{10,}`)},
+	{kind: "credit card number", re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), isValid: luhn},
+}
+
+// personal detectors find contact details. They are too common in legitimate messages
+// to block on, so only NewWithPersonalData uses them.
+var personal = []detector{
+	{kind: "email", re: regexp.MustCompile(`\b[0-9A-Za-z._%+-]+@[0-9A-Za-z.-]+\.[A-Za-z]{2,}\b`)},
+	{kind: "phone number", re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?\d{3,4}[ .-]\d{3,4}|\b\d{2,4}[ .-]\d{3,4}[ .-]\d{3,4})\b|\+\d{8,15}\b`)},
+}
+
+// Scanner runs the built-in detectors and any custom patterns.
+type Scanner struct {
+	detectors []detector
+}
+
+// New returns a scanner with the built-in detectors plus the given regular expressions,
+// which are reported as "custom pattern".
+func New(patterns []string) (*Scanner, error) {
+	return newScanner(builtin, patterns)
+}
+
+// NewWithPersonalData is like New but also detects email addresses and phone numbers.
+func NewWithPersonalData(patterns []string) (*Scanner, error) {
+	return newScanner(append(append([]detector(nil), builtin...), personal...), patterns)
+}
+
+func newScanner(base []detector, patterns []string) (*Scanner, error) {
+	detectors := append([]detector(nil), base...)
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan pattern %q: %w", p, err)
+		}
+		detectors = append(detectors, detector{kind: "custom pattern", re: re})
+	}
+	return &Scanner{detectors: detectors}, nil
+}
+
+// Scan returns the non-overlapping findings in text, ordered by position.
+func (s *Scanner) Scan(text string) []Finding {
+	var findings []Finding
+	for _, d := range s.detectors {
+		for _, loc := range d.re.FindAllStringIndex(text, -1) {
+			if d.isValid != nil && !d.isValid(text[loc[0]:loc[1]]) {
+				continue
+			}
+			f := Finding{Kind: d.kind, Start: loc[0], End: loc[1]}
+			if !overlaps(findings, f) {
+				findings = append(findings, f)
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+func overlaps(findings []Finding, f Finding) bool {
+	for _, existing := range findings {
+		if f.Start < existing.End && existing.Start < f.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces every finding with a "[REDACTED <kind>]" marker.
+func Redact(text string, findings []Finding) string {
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(text[last:f.Start])
+		b.WriteString("[REDACTED " + f.Kind + "]")
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Redactor masks findings with numbered placeholders such as "REDACTED_EMAIL_2". A value
+// keeps its placeholder for the lifetime of the Redactor, so a conversation stays
+// readable when the same address appears in several messages.
+type Redactor struct {
+	scanner      *Scanner
+	placeholders map[string]string
+	counts       map[string]int
+}
+
+// NewRedactor returns a Redactor using s.
+func NewRedactor(s *Scanner) *Redactor {
+	return &Redactor{
+		scanner:      s,
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// Redact replaces every finding in text with its placeholder.
+func (r *Redactor) Redact(text string) string {
+	findings := r.scanner.Scan(text)
+	if len(findings) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(text[last:f.Start])
+		b.WriteString(r.placeholder(f.Kind, text[f.Start:f.End]))
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (r *Redactor) placeholder(kind, value string) string {
+	key := kind + "\x00" + value
+	if p, ok := r.placeholders[key]; ok {
+		return p
+	}
+	r.counts[kind]++
+	label := strings.ToUpper(strings.ReplaceAll(kind, " ", "_"))
+	p := fmt.Sprintf("REDACTED_%s_%d", label, r.counts[kind])
+	r.placeholders[key] = p
+	return p
+}
+
+// Summary describes the findings without revealing them, e.g. "Slack token, 2x JWT".
+func Summary(findings []Finding) string {
+	counts := make(map[string]int)
+	var kinds []string
+	for _, f := range findings {
+		if counts[f.Kind] == 0 {
+			kinds = append(kinds, f.Kind)
+		}
+		counts[f.Kind]++
+	}
+	parts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		if counts[k] > 1 {
+			parts = append(parts, fmt.Sprintf("%dx %s", counts[k], k))
+		} else {
+			parts = append(parts, k)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// luhn validates a card number candidate, ignoring spaces and dashes.
+func luhn(candidate string) bool {
+	var digits []int
+	for _, r := range candidate {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"go.uber.org/zap"
+)
+
+const (
+	approvalsPath   = "/approvals"
+	approvalsCookie = "slack_mcp_approvals"
+	// recentActions is how many reviewed actions the review page lists.
+	recentActions = 20
+)
+
+//go:embed approvals.html
+var approvalsPage string
+
+var approvalsTemplate = template.Must(template.New("approvals").Parse(approvalsPage))
+
+// openApprovalQueue opens the approval queue configured by SLACK_MCP_APPROVAL, or
+// returns nil when approval mode is disabled.
+func openApprovalQueue(logger *zap.Logger) *approval.Queue {
+	cfg := config.Current().Approval
+	if !cfg.Enabled {
+		return nil
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = approval.DefaultDir()
+	}
+
+	queue, err := approval.Open(dir)
+	if err != nil {
+		logger.Fatal("Failed to open approval queue",
+			zap.String("context", "console"),
+			zap.String("dir", dir),
+			zap.Error(err),
+		)
+	}
+
+	logger.Info("Approval mode enabled, write actions are queued for review",
+		zap.String("context", "console"),
+		zap.String("dir", dir),
+	)
+	return queue
+}
+
+// newApprovalDispatcher wires the executors of the write tools to the queue.
+func newApprovalDispatcher(queue *approval.Queue, conversations *handler.ConversationsHandler, auditLog *audit.Log, transport string, logger *zap.Logger) *approval.Dispatcher {
+	d := approval.NewDispatcher(queue, logger)
+	d.Handle("conversations_add_message", auditedExecutor(auditLog, transport, conversations.PostApprovedMessage, logger))
+	return d
+}
+
+// auditedExecutor records approved actions in the audit log like direct tool calls, with
+// the reviewer as the caller.
+func auditedExecutor(auditLog *audit.Log, transport string, exec approval.Executor, logger *zap.Logger) approval.Executor {
+	if auditLog == nil {
+		return exec
+	}
+	return func(ctx context.Context, a *approval.Action) (string, string, error) {
+		ts, permalink, err := exec(ctx, a)
+
+		entry := audit.Entry{
+			Tool:        a.Tool,
+			Caller:      "approval:" + a.Reviewer,
+			Transport:   transport,
+			Channel:     a.Channel,
+			ContentHash: audit.HashContent(a.Text),
+			TS:          ts,
+			Permalink:   permalink,
+			Outcome:     audit.OutcomeSuccess,
+		}
+		if err != nil {
+			entry.Outcome = audit.OutcomeError
+			entry.Error = err.Error()
+		}
+		if rErr := auditLog.Record(entry); rErr != nil {
+			logger.Error("Failed to write audit entry",
+				zap.String("tool", a.Tool),
+				zap.String("id", a.ID),
+				zap.Error(rErr),
+			)
+		}
+		return ts, permalink, err
+	}
+}
+
+// RunApprovals posts approved actions until ctx is cancelled. It returns immediately
+// when approval mode is disabled.
+func (s *MCPServer) RunApprovals(ctx context.Context) {
+	if s.dispatcher == nil {
+		return
+	}
+	s.dispatcher.Run(ctx)
+}
+
+// approvalsUI serves the review page. Reviewers authenticate once with
+// /approvals?key=<reviewer key>, which sets a cookie derived from the key. The MCP API
+// key and OAuth bearer tokens are never accepted: the agent holds them and could
+// approve its own actions.
+type approvalsUI struct {
+	queue  *approval.Queue
+	csrf   string
+	logger *zap.Logger
+}
+
+func newApprovalsUI(queue *approval.Queue, logger *zap.Logger) *approvalsUI {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return &approvalsUI{queue: queue, csrf: hex.EncodeToString(b), logger: logger}
+}
+
+// mountApprovals registers the review page when approval mode is enabled and a
+// reviewer key is configured.
+func (s *MCPServer) mountApprovals(mux *http.ServeMux) {
+	if s.approvals == nil {
+		return
+	}
+	if config.Current().Approval.ReviewerKey == "" {
+		s.logger.Warn("Approval review page disabled, set SLACK_MCP_APPROVAL_REVIEWER_KEY to serve it; the approvals CLI is still available",
+			zap.String("context", "console"),
+		)
+		return
+	}
+	ui := newApprovalsUI(s.approvals, s.logger)
+	mux.HandleFunc("GET "+approvalsPath, ui.list)
+	mux.HandleFunc("POST "+approvalsPath+"/{id}/{op}", ui.review)
+}
+
+// cookieValue derives the session cookie from the reviewer key, so the key itself is
+// never stored in the browser.
+func cookieValue(key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(approvalsCookie))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// authorize reports whether the request may use the review page. It handles the
+// ?key= login itself, answering with a redirect.
+func (ui *approvalsUI) authorize(w http.ResponseWriter, r *http.Request) bool {
+	key := config.Current().Approval.ReviewerKey
+	if key == "" {
+		http.Error(w, "the review page requires SLACK_MCP_APPROVAL_REVIEWER_KEY", http.StatusForbidden)
+		return false
+	}
+	if given := r.URL.Query().Get("key"); given != "" {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+			http.Error(w, "invalid key", http.StatusUnauthorized)
+			return false
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     approvalsCookie,
+			Value:    cookieValue(key),
+			Path:     approvalsPath,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteStrictMode,
+		})
+		http.Redirect(w, r, approvalsPath, http.StatusSeeOther)
+		return false
+	}
+	if c, err := r.Cookie(approvalsCookie); err == nil &&
+		subtle.ConstantTimeCompare([]byte(c.Value), []byte(cookieValue(key))) == 1 {
+		return true
+	}
+	http.Error(w, "unauthorized: open "+approvalsPath+"?key=<SLACK_MCP_APPROVAL_REVIEWER_KEY> to sign in", http.StatusUnauthorized)
+	return false
+}
+
+type approvalsView struct {
+	Pending []*approval.Action
+	Recent  []*approval.Action
+	CSRF    string
+	Flash   string
+}
+
+func (ui *approvalsUI) list(w http.ResponseWriter, r *http.Request) {
+	if !ui.authorize(w, r) {
+		return
+	}
+
+	actions, err := ui.queue.List()
+	if err != nil {
+		ui.logger.Error("Failed to list approval queue", zap.Error(err))
+		http.Error(w, "failed to read approval queue", http.StatusInternalServerError)
+		return
+	}
+	view := approvalsView{CSRF: ui.csrf, Flash: r.URL.Query().Get("flash")}
+	for i := len(actions) - 1; i >= 0; i-- {
+		a := actions[i]
+		switch {
+		case a.Status == approval.StatusPending:
+			view.Pending = append([]*approval.Action{a}, view.Pending...)
+		case len(view.Recent) < recentActions:
+			view.Recent = append(view.Recent, a)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	if err := approvalsTemplate.Execute(w, view); err != nil {
+		ui.logger.Error("Failed to render approvals page", zap.Error(err))
+	}
+}
+
+func (ui *approvalsUI) review(w http.ResponseWriter, r *http.Request) {
+	if !ui.authorize(w, r) {
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.FormValue("csrf")), []byte(ui.csrf)) != 1 {
+		http.Error(w, "invalid or expired form, reload the page", http.StatusForbidden)
+		return
+	}
+
+	id, op := r.PathValue("id"), r.PathValue("op")
+	reviewer := "web:" + remoteHost(r)
+	text := strings.ReplaceAll(r.FormValue("text"), "\r\n", "\n")
+
+	var err error
+	switch op {
+	case "approve":
+		if a, gErr := ui.queue.Get(id); gErr == nil && text != "" && text != a.Text {
+			_, err = ui.queue.Edit(id, text, reviewer)
+		}
+		if err == nil {
+			_, err = ui.queue.Approve(id, reviewer)
+		}
+	case "edit":
+		_, err = ui.queue.Edit(id, text, reviewer)
+	case "reject":
+		_, err = ui.queue.Reject(id, reviewer, r.FormValue("reason"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	flash := id + ": " + op + " done"
+	if err != nil {
+		flash = id + ": " + err.Error()
+		if !errors.Is(err, approval.ErrNotFound) && !errors.Is(err, approval.ErrInvalidState) {
+			ui.logger.Error("Failed to update approval queue", zap.String("id", id), zap.Error(err))
+		}
+	} else {
+		ui.logger.Info("Action reviewed",
+			zap.String("id", id),
+			zap.String("op", op),
+			zap.String("reviewer", reviewer),
+		)
+	}
+	http.Redirect(w, r, approvalsPath+"?flash="+url.QueryEscape(flash), http.StatusSeeOther)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newApprovalsMux(t *testing.T) (*http.ServeMux, *approval.Queue) {
+	t.Helper()
+	queue, err := approval.Open(t.TempDir())
+	require.NoError(t, err)
+
+	s := &MCPServer{approvals: queue, logger: zap.NewNop()}
+	mux := http.NewServeMux()
+	s.mountApprovals(mux)
+	return mux, queue
+}
+
+func TestUnitApprovalsDisabled(t *testing.T) {
+	s := &MCPServer{logger: zap.NewNop()}
+	mux := http.NewServeMux()
+	s.mountApprovals(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, approvalsPath, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestUnitApprovalsWithoutReviewerKey(t *testing.T) {
+	t.Setenv("SLACK_MCP_API_KEY", "")
+	t.Setenv("SLACK_MCP_APPROVAL_REVIEWER_KEY", "")
+	mux, _ := newApprovalsMux(t)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, approvalsPath, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	queue, err := approval.Open(t.TempDir())
+	require.NoError(t, err)
+	rec = httptest.NewRecorder()
+	newApprovalsUI(queue, zap.NewNop()).list(rec, httptest.NewRequest(http.MethodGet, approvalsPath, nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestUnitApprovalsAuth(t *testing.T) {
+	t.Setenv("SLACK_MCP_API_KEY", "mcp-token")
+	t.Setenv("SLACK_MCP_APPROVAL_REVIEWER_KEY", "secret")
+	mux, queue := newApprovalsMux(t)
+	require.NoError(t, queue.Enqueue(&approval.Action{Tool: "conversations_add_message", Channel: "C1", Text: "<b>hi</b>"}))
+
+	get := func(target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get(approvalsPath, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, get(approvalsPath+"?key=wrong", nil).Code)
+
+	login := get(approvalsPath+"?key=secret", nil)
+	require.Equal(t, http.StatusSeeOther, login.Code)
+	cookies := login.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.NotContains(t, cookies[0].Value, "secret")
+
+	page := get(approvalsPath, cookies[0])
+	require.Equal(t, http.StatusOK, page.Code)
+	assert.Contains(t, page.Body.String(), "&lt;b&gt;hi&lt;/b&gt;")
+}
+
+func TestUnitApprovalsRejectMCPToken(t *testing.T) {
+	t.Setenv("SLACK_MCP_API_KEY", "mcp-token")
+	t.Setenv("SLACK_MCP_APPROVAL_REVIEWER_KEY", "secret")
+	mux, _ := newApprovalsMux(t)
+
+	for _, token := range []string{"mcp-token", "secret"} {
+		req := httptest.NewRequest(http.MethodGet, approvalsPath, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, token)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, approvalsPath+"?key=mcp-token", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestUnitApprovalsReview(t *testing.T) {
+	t.Setenv("SLACK_MCP_APPROVAL_REVIEWER_KEY", "secret")
+	queue, err := approval.Open(t.TempDir())
+	require.NoError(t, err)
+	ui := newApprovalsUI(queue, zap.NewNop())
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+approvalsPath+"/{id}/{op}", ui.review)
+
+	post := func(id, op string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, approvalsPath+"/"+id+"/"+op, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: approvalsCookie, Value: cookieValue("secret")})
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	edited := &approval.Action{Tool: "conversations_add_message", Channel: "C1", Text: "draft"}
+	rejected := &approval.Action{Tool: "conversations_add_message", Channel: "C2", Text: "nope"}
+	require.NoError(t, queue.Enqueue(edited))
+	require.NoError(t, queue.Enqueue(rejected))
+
+	rec := post(edited.ID, "approve", url.Values{"csrf": {"forged"}, "text": {"final"}})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = post(edited.ID, "approve", url.Values{"csrf": {ui.csrf}, "text": {"final\r\ntext"}})
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	got, err := queue.Get(edited.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approval.StatusApproved, got.Status)
+	assert.Equal(t, "final\ntext", got.Text)
+	assert.True(t, got.Edited)
+	assert.Equal(t, "web:192.0.2.1", got.Reviewer)
+
+	rec = post(rejected.ID, "reject", url.Values{"csrf": {ui.csrf}, "reason": {"off topic"}})
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	got, err = queue.Get(rejected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approval.StatusRejected, got.Status)
+	assert.Equal(t, "off topic", got.Reason)
+
+	rec = post(rejected.ID, "approve", url.Values{"csrf": {ui.csrf}})
+	assert.Contains(t, rec.Header().Get("Location"), url.QueryEscape(approval.ErrInvalidState.Error()))
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// openAuditLog opens the audit log configured by SLACK_MCP_AUDIT_LOG, or returns nil
+// when auditing is disabled.
+func openAuditLog(logger *zap.Logger) *audit.Log {
+	path := config.Current().Server.AuditLog
+	if path == "" {
+		return nil
+	}
+
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		logger.Fatal("Failed to open audit log",
+			zap.String("context", "console"),
+			zap.String("path", path),
+			zap.Error(err),
+		)
+	}
+
+	logger.Info("Audit log enabled",
+		zap.String("context", "console"),
+		zap.String("path", path),
+	)
+	return auditLog
+}
+
+// buildAuditMiddleware records every call to a tool that is not annotated as read-only,
+// including calls rejected before reaching the handler.
+func buildAuditMiddleware(auditLog *audit.Log, transport string, logger *zap.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if auditLog == nil || auth.IsReadOnlyTool(ctx, req.Params.Name) {
+				return next(ctx, req)
+			}
+
+			ev := &audit.Event{}
+			res, err := next(audit.WithEvent(ctx, ev), req)
+
+			entry := audit.Entry{
+				Tool:      req.Params.Name,
+				Caller:    auth.Caller(ctx, transport),
+				Transport: transport,
+				Channel:   req.GetString("channel_id", ""),
+				Outcome:   audit.OutcomeSuccess,
+			}
+			if args, mErr := json.Marshal(req.GetArguments()); mErr == nil {
+				entry.ContentHash = audit.HashContent(string(args))
+			}
+			ev.Apply(&entry)
+
+			switch {
+			case err != nil:
+				entry.Outcome = audit.OutcomeError
+				entry.Error = err.Error()
+			case res != nil && res.IsError:
+				entry.Outcome = audit.OutcomeError
+				entry.Error = toolResultText(res)
+			}
+
+			if rErr := auditLog.Record(entry); rErr != nil {
+				logger.Error("Failed to write audit entry",
+					zap.String("tool", req.Params.Name),
+					zap.Error(rErr),
+				)
+			}
+
+			return res, err
+		}
+	}
+}
+
+func toolResultText(res *mcp.CallToolResult) string {
+	for _, c := range res.Content {
+		if tc, ok := mcp.AsTextContent(c); ok {
+			return tc.Text
+		}
+	}
+	return ""
+}
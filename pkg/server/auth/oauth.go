@@ -0,0 +1,562 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReadScope  = "slack:read"
+	defaultWriteScope = "slack:write"
+
+	// ProtectedResourceMetadataPath is the RFC 9728 well-known path that MCP clients
+	// query to discover which authorization server issues tokens for this server.
+	ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+	jwksRefreshInterval = time.Minute
+	clockLeeway         = time.Minute
+)
+
+// allowedSigningAlgs lists asymmetric JWS algorithms accepted for access tokens.
+// Symmetric (HS*) and "none" are rejected because the key would have to be shared with us.
+var allowedSigningAlgs = map[string]bool{
+	string(jose.RS256): true,
+	string(jose.RS384): true,
+	string(jose.RS512): true,
+	string(jose.PS256): true,
+	string(jose.PS384): true,
+	string(jose.PS512): true,
+	string(jose.ES256): true,
+	string(jose.ES384): true,
+	string(jose.ES512): true,
+	string(jose.EdDSA): true,
+}
+
+var (
+	ErrMissingToken      = errors.New("missing bearer token")
+	ErrInvalidToken      = errors.New("invalid bearer token")
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Permission is a coarse capability granted to a caller by its token scopes.
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+)
+
+// OAuthConfig describes how bearer tokens presented to the HTTP transport are validated.
+type OAuthConfig struct {
+	// Issuer is the authorization server identifier, matched against the "iss" claim.
+	Issuer string
+	// Resource is the canonical URL of this MCP server, advertised in the metadata document.
+	Resource string
+	// Audience is matched against the "aud" claim, defaults to Resource.
+	Audience string
+	// JWKSURL overrides the key set location discovered from the issuer metadata.
+	JWKSURL string
+	// IntrospectionURL switches validation from local JWT verification to RFC 7662 introspection.
+	IntrospectionURL string
+	ClientID         string
+	ClientSecret     string
+	// ReadScope and WriteScope map token scopes onto read and write permissions.
+	ReadScope  string
+	WriteScope string
+}
+
+// LoadOAuthConfig reads OAuth settings from the server configuration.
+// It returns nil when no issuer is configured, meaning OAuth is disabled.
+func LoadOAuthConfig() (*OAuthConfig, error) {
+	oauth := config.Current().OAuth
+	if oauth.Issuer == "" {
+		return nil, nil
+	}
+
+	cfg := &OAuthConfig{
+		Issuer:           oauth.Issuer,
+		Resource:         oauth.Resource,
+		Audience:         oauth.Audience,
+		JWKSURL:          oauth.JWKSURL,
+		IntrospectionURL: oauth.IntrospectionURL,
+		ClientID:         oauth.ClientID,
+		ClientSecret:     oauth.ClientSecret,
+		ReadScope:        oauth.ReadScope,
+		WriteScope:       oauth.WriteScope,
+	}
+
+	if cfg.ReadScope == "" {
+		cfg.ReadScope = defaultReadScope
+	}
+	if cfg.WriteScope == "" {
+		cfg.WriteScope = defaultWriteScope
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the configuration is complete enough to validate tokens safely.
+func (c *OAuthConfig) Validate() error {
+	if _, err := url.ParseRequestURI(c.Issuer); err != nil {
+		return fmt.Errorf("invalid SLACK_MCP_OAUTH_ISSUER %q: %w", c.Issuer, err)
+	}
+	if c.Resource == "" {
+		return errors.New("SLACK_MCP_OAUTH_RESOURCE must be set to the public URL of the MCP endpoint, e.g. https://mcp.example.com/mcp")
+	}
+	if _, err := url.ParseRequestURI(c.Resource); err != nil {
+		return fmt.Errorf("invalid SLACK_MCP_OAUTH_RESOURCE %q: %w", c.Resource, err)
+	}
+	if c.IntrospectionURL != "" && c.ClientID == "" {
+		return errors.New("SLACK_MCP_OAUTH_CLIENT_ID is required when SLACK_MCP_OAUTH_INTROSPECTION_URL is set")
+	}
+	return nil
+}
+
+func (c *OAuthConfig) audience() string {
+	if c.Audience != "" {
+		return c.Audience
+	}
+	return c.Resource
+}
+
+// MetadataURL returns the absolute URL of the protected resource metadata document.
+// Per RFC 9728 the resource path is appended to the well-known prefix.
+func (c *OAuthConfig) MetadataURL() string {
+	u, err := url.Parse(c.Resource)
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	u.Path = ProtectedResourceMetadataPath + path
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// Principal is the identity behind a validated access token.
+type Principal struct {
+	Subject  string
+	ClientID string
+	Scopes   []string
+
+	permissions map[Permission]bool
+}
+
+// Can reports whether the principal holds the given permission. Write implies read.
+func (p *Principal) Can(perm Permission) bool {
+	if p == nil {
+		return false
+	}
+	if perm == PermissionRead && p.permissions[PermissionWrite] {
+		return true
+	}
+	return p.permissions[perm]
+}
+
+// Name returns a stable, human readable caller identity for logs.
+func (p *Principal) Name() string {
+	if p == nil {
+		return ""
+	}
+	if p.Subject != "" {
+		return p.Subject
+	}
+	return p.ClientID
+}
+
+// principalKey is a custom context key for storing the validated principal.
+type principalKey struct{}
+
+// WithPrincipal adds a validated principal to the context.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the OAuth handler, if any.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+func newPrincipal(cfg *OAuthConfig, subject, clientID string, scopes []string) *Principal {
+	p := &Principal{
+		Subject:     subject,
+		ClientID:    clientID,
+		Scopes:      scopes,
+		permissions: make(map[Permission]bool),
+	}
+	for _, s := range scopes {
+		switch s {
+		case cfg.ReadScope:
+			p.permissions[PermissionRead] = true
+		case cfg.WriteScope:
+			p.permissions[PermissionWrite] = true
+		}
+	}
+	return p
+}
+
+// TokenValidator turns a raw bearer token into a principal.
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (*Principal, error)
+}
+
+// NewTokenValidator picks introspection when an endpoint is configured and local JWT
+// verification against the issuer's JWKS otherwise.
+func NewTokenValidator(cfg *OAuthConfig, client *http.Client) TokenValidator {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.IntrospectionURL != "" {
+		return &introspectionValidator{cfg: cfg, client: client}
+	}
+	return &jwksValidator{cfg: cfg, client: client}
+}
+
+type jwksValidator struct {
+	cfg    *OAuthConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+type tokenClaims struct {
+	Scope    string          `json:"scope"`
+	Scp      json.RawMessage `json:"scp"`
+	ClientID string          `json:"client_id"`
+	AZP      string          `json:"azp"`
+}
+
+func (v *jwksValidator) Validate(ctx context.Context, raw string) (*Principal, error) {
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(tok.Headers) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one signature", ErrInvalidToken)
+	}
+	hdr := tok.Headers[0]
+	if !allowedSigningAlgs[hdr.Algorithm] {
+		return nil, fmt.Errorf("%w: signing algorithm %q is not allowed", ErrInvalidToken, hdr.Algorithm)
+	}
+
+	key, err := v.key(ctx, hdr.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		std    jwt.Claims
+		custom tokenClaims
+	)
+	if err := tok.Claims(key.Key, &std, &custom); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if std.Expiry == nil {
+		return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+	}
+	err = std.ValidateWithLeeway(jwt.Expected{
+		Issuer:   v.cfg.Issuer,
+		Audience: jwt.Audience{v.cfg.audience()},
+		Time:     time.Now(),
+	}, clockLeeway)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	clientID := custom.ClientID
+	if clientID == "" {
+		clientID = custom.AZP
+	}
+
+	return newPrincipal(v.cfg, std.Subject, clientID, parseScopes(custom.Scope, custom.Scp)), nil
+}
+
+// key returns the verification key for kid, refreshing the key set at most once per
+// jwksRefreshInterval so that rotated keys are picked up without a restart.
+func (v *jwksValidator) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.keys != nil {
+		if k := lookupKey(v.keys, kid); k != nil {
+			return k, nil
+		}
+		if time.Since(v.fetchedAt) < jwksRefreshInterval {
+			return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+		}
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if k := lookupKey(v.keys, kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+}
+
+func (v *jwksValidator) refresh(ctx context.Context) error {
+	if v.jwksURL == "" {
+		v.jwksURL = v.cfg.JWKSURL
+	}
+	if v.jwksURL == "" {
+		u, err := discoverJWKSURL(ctx, v.client, v.cfg.Issuer)
+		if err != nil {
+			return err
+		}
+		v.jwksURL = u
+	}
+
+	var set jose.JSONWebKeySet
+	if err := getJSON(ctx, v.client, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	v.keys = &set
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+func lookupKey(set *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	for i := range set.Keys {
+		k := &set.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if kid == "" || k.KeyID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// discoverJWKSURL reads the authorization server metadata (RFC 8414), falling back to
+// OpenID Connect discovery for issuers that only publish the latter.
+func discoverJWKSURL(ctx context.Context, client *http.Client, issuer string) (string, error) {
+	base := strings.TrimSuffix(issuer, "/")
+	var lastErr error
+	for _, wk := range []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"} {
+		var meta struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := getJSON(ctx, client, base+wk, &meta); err != nil {
+			lastErr = err
+			continue
+		}
+		if meta.JWKSURI == "" {
+			lastErr = fmt.Errorf("metadata at %s has no jwks_uri", base+wk)
+			continue
+		}
+		return meta.JWKSURI, nil
+	}
+	return "", fmt.Errorf("failed to discover JWKS for issuer %q: %w", issuer, lastErr)
+}
+
+type introspectionValidator struct {
+	cfg    *OAuthConfig
+	client *http.Client
+}
+
+type introspectionResponse struct {
+	Active   bool             `json:"active"`
+	Scope    string           `json:"scope"`
+	ClientID string           `json:"client_id"`
+	Subject  string           `json:"sub"`
+	Issuer   string           `json:"iss"`
+	Audience jwt.Audience     `json:"aud"`
+	Expiry   *jwt.NumericDate `json:"exp"`
+}
+
+func (v *introspectionValidator) Validate(ctx context.Context, raw string) (*Principal, error) {
+	form := url.Values{}
+	form.Set("token", raw)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(v.cfg.ClientID), url.QueryEscape(v.cfg.ClientSecret))
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token introspection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token introspection failed: unexpected status %d", resp.StatusCode)
+	}
+
+	var ir introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ir); err != nil {
+		return nil, fmt.Errorf("token introspection failed: %w", err)
+	}
+
+	if !ir.Active {
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+	if ir.Issuer != "" && ir.Issuer != v.cfg.Issuer {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, jwt.ErrInvalidIssuer)
+	}
+	if len(ir.Audience) > 0 && !ir.Audience.Contains(v.cfg.audience()) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, jwt.ErrInvalidAudience)
+	}
+	if ir.Expiry != nil && time.Now().Add(-clockLeeway).After(ir.Expiry.Time()) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, jwt.ErrExpired)
+	}
+
+	return newPrincipal(v.cfg, ir.Subject, ir.ClientID, strings.Fields(ir.Scope)), nil
+}
+
+// parseScopes accepts the RFC 8693 space-delimited "scope" claim as well as the
+// "scp" claim used by some providers, which may be either a string or an array.
+func parseScopes(scope string, scp json.RawMessage) []string {
+	scopes := strings.Fields(scope)
+	if len(scp) == 0 {
+		return scopes
+	}
+
+	var list []string
+	if err := json.Unmarshal(scp, &list); err == nil {
+		return append(scopes, list...)
+	}
+	var s string
+	if err := json.Unmarshal(scp, &s); err == nil {
+		return append(scopes, strings.Fields(s)...)
+	}
+	return scopes
+}
+
+func getJSON(ctx context.Context, client *http.Client, u string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// ProtectedResourceMetadataHandler serves the RFC 9728 metadata document that points
+// clients at the authorization server.
+func ProtectedResourceMetadataHandler(cfg *OAuthConfig) http.Handler {
+	doc := map[string]interface{}{
+		"resource":                 cfg.Resource,
+		"authorization_servers":    []string{cfg.Issuer},
+		"scopes_supported":         []string{cfg.ReadScope, cfg.WriteScope},
+		"bearer_methods_supported": []string{"header"},
+		"resource_name":            "Slack MCP Server",
+	}
+	body, _ := json.Marshal(doc)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		_, _ = w.Write(body)
+	})
+}
+
+// OAuthHandler wraps the MCP endpoint, rejecting requests without a valid bearer token
+// with a WWW-Authenticate challenge so clients can start the authorization flow.
+func OAuthHandler(cfg *OAuthConfig, validator TokenValidator, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			logger.Debug("Missing bearer token",
+				zap.String("context", "http"),
+				zap.String("path", r.URL.Path),
+			)
+			writeChallenge(w, cfg, http.StatusUnauthorized, "", "")
+			return
+		}
+
+		principal, err := validator.Validate(r.Context(), raw)
+		if err != nil {
+			logger.Warn("Bearer token rejected",
+				zap.String("context", "http"),
+				zap.Error(err),
+			)
+			if errors.Is(err, ErrInvalidToken) {
+				writeChallenge(w, cfg, http.StatusUnauthorized, "invalid_token", err.Error())
+			} else {
+				http.Error(w, "authorization server unavailable", http.StatusServiceUnavailable)
+			}
+			return
+		}
+
+		if !principal.Can(PermissionRead) {
+			logger.Warn("Bearer token lacks read scope",
+				zap.String("context", "http"),
+				zap.String("subject", principal.Name()),
+				zap.Strings("scopes", principal.Scopes),
+			)
+			writeChallenge(w, cfg, http.StatusForbidden, "insufficient_scope", "token is missing the "+cfg.ReadScope+" scope")
+			return
+		}
+
+		logger.Debug("Bearer token validated",
+			zap.String("context", "http"),
+			zap.String("subject", principal.Name()),
+		)
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func writeChallenge(w http.ResponseWriter, cfg *OAuthConfig, status int, code, description string) {
+	params := []string{fmt.Sprintf("resource_metadata=%q", cfg.MetadataURL())}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", description))
+	}
+	if code == "insufficient_scope" {
+		params = append(params, fmt.Sprintf("scope=%q", cfg.ReadScope))
+	}
+
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// testIssuer is a stand-in authorization server publishing RFC 8414 metadata and a JWKS.
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ti := &testIssuer{key: key, kid: "test-key"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   ti.server.URL,
+			"jwks_uri": ti.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+			Key:       &key.PublicKey,
+			KeyID:     ti.kid,
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}}})
+	})
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "mcp" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = r.ParseForm()
+		switch r.PostForm.Get("token") {
+		case "active-token":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"active":    true,
+				"scope":     "slack:read slack:write",
+				"sub":       "alice",
+				"client_id": "claude",
+				"iss":       ti.server.URL,
+				"aud":       testResource,
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"active": false})
+		}
+	})
+	ti.server = httptest.NewServer(mux)
+	t.Cleanup(ti.server.Close)
+
+	return ti
+}
+
+func (ti *testIssuer) config() *OAuthConfig {
+	return &OAuthConfig{
+		Issuer:     ti.server.URL,
+		Resource:   testResource,
+		ReadScope:  defaultReadScope,
+		WriteScope: defaultWriteScope,
+	}
+}
+
+func (ti *testIssuer) token(t *testing.T, claims jwt.Claims, scope string) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: ti.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", ti.kid),
+	)
+	require.NoError(t, err)
+
+	raw, err := jwt.Signed(signer).Claims(claims).Claims(map[string]interface{}{"scope": scope}).CompactSerialize()
+	require.NoError(t, err)
+	return raw
+}
+
+func (ti *testIssuer) claims(aud string, ttl time.Duration) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:   ti.server.URL,
+		Subject:  "alice",
+		Audience: jwt.Audience{aud},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+func TestUnitJWKSValidator(t *testing.T) {
+	ti := newTestIssuer(t)
+	cfg := ti.config()
+	validator := NewTokenValidator(cfg, ti.server.Client())
+	ctx := context.Background()
+
+	t.Run("valid token grants scopes", func(t *testing.T) {
+		p, err := validator.Validate(ctx, ti.token(t, ti.claims(testResource, time.Hour), "slack:read"))
+		require.NoError(t, err)
+		assert.Equal(t, "alice", p.Subject)
+		assert.True(t, p.Can(PermissionRead))
+		assert.False(t, p.Can(PermissionWrite))
+	})
+
+	t.Run("write scope implies read", func(t *testing.T) {
+		p, err := validator.Validate(ctx, ti.token(t, ti.claims(testResource, time.Hour), "slack:write"))
+		require.NoError(t, err)
+		assert.True(t, p.Can(PermissionRead))
+		assert.True(t, p.Can(PermissionWrite))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := validator.Validate(ctx, ti.token(t, ti.claims(testResource, -time.Hour), "slack:read"))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		_, err := validator.Validate(ctx, ti.token(t, ti.claims("https://other.example.com", time.Hour), "slack:read"))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("foreign signing key", func(t *testing.T) {
+		other := newTestIssuer(t)
+		raw := other.token(t, ti.claims(testResource, time.Hour), "slack:read")
+		_, err := validator.Validate(ctx, raw)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		_, err := validator.Validate(ctx, "not-a-jwt")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestUnitIntrospectionValidator(t *testing.T) {
+	ti := newTestIssuer(t)
+	cfg := ti.config()
+	cfg.IntrospectionURL = ti.server.URL + "/introspect"
+	cfg.ClientID = "mcp"
+	cfg.ClientSecret = "secret"
+	validator := NewTokenValidator(cfg, ti.server.Client())
+
+	p, err := validator.Validate(context.Background(), "active-token")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Name())
+	assert.Equal(t, "claude", p.ClientID)
+	assert.True(t, p.Can(PermissionWrite))
+
+	_, err = validator.Validate(context.Background(), "revoked-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestUnitOAuthHandler(t *testing.T) {
+	ti := newTestIssuer(t)
+	cfg := ti.config()
+	validator := NewTokenValidator(cfg, ti.server.Client())
+
+	var seen *Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	h := OAuthHandler(cfg, validator, zap.NewNop(), next)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{
+			name:          "missing token",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`,
+		},
+		{
+			name:          "invalid token",
+			authorization: "Bearer nope",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `error="invalid_token"`,
+		},
+		{
+			name:          "missing read scope",
+			authorization: "Bearer " + ti.token(t, ti.claims(testResource, time.Hour), "profile"),
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: `error="insufficient_scope"`,
+		},
+		{
+			name:          "valid token",
+			authorization: "Bearer " + ti.token(t, ti.claims(testResource, time.Hour), "slack:read"),
+			wantStatus:    http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}"))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantChallenge != "" {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), tt.wantChallenge)
+				assert.Nil(t, seen)
+			} else {
+				require.NotNil(t, seen)
+				assert.Equal(t, "alice", seen.Subject)
+			}
+		})
+	}
+}
+
+func TestUnitProtectedResourceMetadataHandler(t *testing.T) {
+	cfg := &OAuthConfig{
+		Issuer:     "https://auth.example.com",
+		Resource:   testResource,
+		ReadScope:  defaultReadScope,
+		WriteScope: defaultWriteScope,
+	}
+
+	rec := httptest.NewRecorder()
+	ProtectedResourceMetadataHandler(cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ProtectedResourceMetadataPath+"/mcp", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc struct {
+		Resource             string   `json:"resource"`
+		AuthorizationServers []string `json:"authorization_servers"`
+		ScopesSupported      []string `json:"scopes_supported"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, testResource, doc.Resource)
+	assert.Equal(t, []string{"https://auth.example.com"}, doc.AuthorizationServers)
+	assert.Equal(t, []string{"slack:read", "slack:write"}, doc.ScopesSupported)
+}
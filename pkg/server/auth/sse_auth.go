@@ -5,9 +5,9 @@ import (
 	"crypto/subtle"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"go.uber.org/zap"
@@ -24,13 +24,7 @@ func withAuthKey(ctx context.Context, auth string) context.Context {
 // Authenticate checks if the request is authenticated based on the provided context.
 func validateToken(ctx context.Context, logger *zap.Logger) (bool, error) {
 	// no configured token means no authentication
-	keyA := os.Getenv("SLACK_MCP_API_KEY")
-	if keyA == "" {
-		keyA = os.Getenv("SLACK_MCP_SSE_API_KEY")
-		if keyA != "" {
-			logger.Warn("SLACK_MCP_SSE_API_KEY is deprecated, please use SLACK_MCP_API_KEY")
-		}
-	}
+	keyA := config.Current().Server.APIKey
 
 	if keyA == "" {
 		logger.Debug("No SSE API key configured, skipping authentication",
@@ -97,6 +91,20 @@ func BuildMiddleware(transport string, logger *zap.Logger) server.ToolHandlerMid
 				return nil, err
 			}
 
+			if principal := PrincipalFromContext(ctx); principal != nil {
+				perm := requiredPermission(ctx, req.Params.Name)
+				if !principal.Can(perm) {
+					logger.Warn("Authorization failed",
+						zap.String("context", "http"),
+						zap.String("transport", transport),
+						zap.String("tool", req.Params.Name),
+						zap.String("subject", principal.Name()),
+						zap.String("permission", string(perm)),
+					)
+					return nil, fmt.Errorf("%w: tool %q requires %s permission", ErrInsufficientScope, req.Params.Name, perm)
+				}
+			}
+
 			logger.Debug("Authentication successful",
 				zap.String("context", "http"),
 				zap.String("transport", transport),
@@ -115,6 +123,17 @@ func IsAuthenticated(ctx context.Context, transport string, logger *zap.Logger)
 		return true, nil
 
 	case "sse", "http":
+		// OAuth principals are validated by OAuthHandler before the request reaches MCP.
+		if PrincipalFromContext(ctx) != nil {
+			return true, nil
+		}
+		if transport == "http" && config.Current().OAuth.Issuer != "" {
+			logger.Warn("HTTP request without OAuth principal",
+				zap.String("context", "http"),
+			)
+			return false, fmt.Errorf("unauthorized request: %w", ErrMissingToken)
+		}
+
 		authenticated, err := validateToken(ctx, logger)
 
 		if err != nil {
@@ -142,3 +161,43 @@ func IsAuthenticated(ctx context.Context, transport string, logger *zap.Logger)
 		return false, fmt.Errorf("unknown transport type: %s", transport)
 	}
 }
+
+// requiredPermission derives the permission a tool needs from its annotations:
+// read-only tools require read, everything else write.
+func requiredPermission(ctx context.Context, tool string) Permission {
+	if IsReadOnlyTool(ctx, tool) {
+		return PermissionRead
+	}
+	return PermissionWrite
+}
+
+// IsReadOnlyTool reports whether the tool registered on the server in ctx is annotated
+// as read-only. mcp-go marks tools as destructive by default, so the read-only hint is
+// the one that matters; unknown tools are treated as mutating.
+func IsReadOnlyTool(ctx context.Context, tool string) bool {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return false
+	}
+	t := srv.GetTool(tool)
+	if t == nil {
+		return false
+	}
+	hint := t.Tool.Annotations.ReadOnlyHint
+	return hint != nil && *hint
+}
+
+// Caller describes who issued the request in ctx, for audit purposes: the OAuth
+// subject when present, "api-key" for requests authenticated with SLACK_MCP_API_KEY,
+// and the transport name otherwise.
+func Caller(ctx context.Context, transport string) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return "oauth:" + principal.Name()
+	}
+	if token, _ := ctx.Value(authKey{}).(string); token != "" && transport != "stdio" {
+		if config.Current().Server.APIKey != "" {
+			return "api-key"
+		}
+	}
+	return transport
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// toolScopes lists the OAuth scopes each tool needs. Every group must be satisfied by
+// at least one of its scopes; a tool with only some scopes of a group works for the
+// matching conversation types only.
+var toolScopes = map[string][][]string{
+	"conversations_history": {
+		{"channels:history", "groups:history", "im:history", "mpim:history"},
+	},
+	"conversations_replies": {
+		{"channels:history", "groups:history", "im:history", "mpim:history"},
+	},
+	"conversations_add_message": {
+		{"chat:write"},
+	},
+	"files_upload": {
+		{"files:write"},
+	},
+	"conversations_search_messages": {
+		{"search:read"},
+	},
+	"channels_list": {
+		{"channels:read", "groups:read", "im:read", "mpim:read"},
+	},
+	"get_image": {
+		{"files:read"},
+	},
+	"get_file": {
+		{"files:read"},
+	},
+}
+
+// featureScopes lists the scopes an optional feature of a tool needs. They are only
+// checked while the feature is enabled, and missing them limits the tool rather than
+// disabling it.
+type featureScopes struct {
+	feature string
+	scopes  []string
+	enabled func() bool
+}
+
+var openDMScopes = featureScopes{
+	feature: "opening new DMs and group DMs",
+	scopes:  []string{"im:write", "mpim:write"},
+	enabled: func() bool { return config.Current().AddMessage.OpenDM },
+}
+
+var toolFeatureScopes = map[string][]featureScopes{
+	"conversations_add_message": {openDMScopes},
+	"files_upload":              {openDMScopes},
+}
+
+// capabilities decides which tools to register based on the tool policy in the config
+// and the scopes granted to the Slack token, and records the outcome for the
+// get_capabilities tool.
+type capabilities struct {
+	policy      config.Tools
+	scopes      map[string]bool
+	scopesKnown bool
+	isBotToken  bool
+	logger      *zap.Logger
+
+	tools []handler.ToolCapability
+}
+
+func newCapabilities(p *provider.ApiProvider, logger *zap.Logger) *capabilities {
+	granted, known := p.GrantedScopes()
+	scopes := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		scopes[s] = true
+	}
+	return &capabilities{
+		policy:      config.Current().Tools,
+		scopes:      scopes,
+		scopesKnown: known,
+		isBotToken:  p.IsBotToken(),
+		logger:      logger,
+	}
+}
+
+// evaluate reports whether tool can be used with the current token.
+func (c *capabilities) evaluate(tool string) handler.ToolCapability {
+	tc := handler.ToolCapability{Tool: tool, Available: true}
+
+	if tool == "conversations_search_messages" && c.isBotToken {
+		tc.Available = false
+		tc.Reason = "bot tokens cannot use search.messages"
+		return tc
+	}
+	if !c.scopesKnown {
+		return tc
+	}
+
+	for _, group := range toolScopes[tool] {
+		missing := c.missing(group)
+		switch {
+		case len(missing) == len(group):
+			tc.Available = false
+			tc.Missing = append(tc.Missing, missing...)
+		case len(missing) > 0:
+			tc.Limited = true
+			tc.Missing = append(tc.Missing, missing...)
+		}
+	}
+
+	var reasons []string
+	switch {
+	case !tc.Available && len(tc.Missing) == 1:
+		tc.Reason = fmt.Sprintf("token is missing the %s scope", tc.Missing[0])
+		return tc
+	case !tc.Available:
+		tc.Reason = fmt.Sprintf("token needs one of the scopes %s", strings.Join(tc.Missing, ", "))
+		return tc
+	case tc.Limited:
+		reasons = append(reasons, fmt.Sprintf("token lacks %s, so the matching conversation types are unavailable", strings.Join(tc.Missing, ", ")))
+	}
+
+	for _, f := range toolFeatureScopes[tool] {
+		if !f.enabled() {
+			continue
+		}
+		if missing := c.missing(f.scopes); len(missing) > 0 {
+			tc.Limited = true
+			tc.Missing = append(tc.Missing, missing...)
+			reasons = append(reasons, fmt.Sprintf("token lacks %s, so %s may fail", strings.Join(missing, ", "), f.feature))
+		}
+	}
+	tc.Reason = strings.Join(reasons, "; ")
+	return tc
+}
+
+// missing returns the scopes that are not granted.
+func (c *capabilities) missing(scopes []string) []string {
+	var missing []string
+	for _, scope := range scopes {
+		if !c.scopes[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// allowed applies the configured tool policy.
+func (c *capabilities) allowed(tool mcp.Tool) (bool, string) {
+	if len(c.policy.Enabled) > 0 && !slices.Contains(c.policy.Enabled, tool.Name) {
+		return false, "not listed in tools.enabled"
+	}
+	if slices.Contains(c.policy.Disabled, tool.Name) {
+		return false, "listed in tools.disabled"
+	}
+	if c.policy.ReadOnly {
+		if hint := tool.Annotations.ReadOnlyHint; hint == nil || !*hint {
+			return false, "server runs in read-only mode"
+		}
+	}
+	return true, ""
+}
+
+// addTool registers tool unless the configuration excludes it or the token cannot use
+// it. Tools that only work for some conversation types get a note appended to their
+// description so clients know up front.
+func (c *capabilities) addTool(s *server.MCPServer, tool mcp.Tool, h server.ToolHandlerFunc) {
+	if ok, reason := c.allowed(tool); !ok {
+		c.tools = append(c.tools, handler.ToolCapability{Tool: tool.Name, Reason: "disabled by configuration: " + reason})
+		c.logger.Info("Tool disabled by configuration",
+			zap.String("context", "console"),
+			zap.String("tool", tool.Name),
+			zap.String("reason", reason),
+		)
+		return
+	}
+
+	tc := c.evaluate(tool.Name)
+	c.tools = append(c.tools, tc)
+
+	if !tc.Available {
+		c.logger.Warn("Tool not registered",
+			zap.String("context", "console"),
+			zap.String("tool", tool.Name),
+			zap.String("reason", tc.Reason),
+		)
+		return
+	}
+	if tc.Limited {
+		tool.Description += fmt.Sprintf(" NOTE: limited by token scopes - %s.", tc.Reason)
+		c.logger.Info("Tool registered with limited scopes",
+			zap.String("context", "console"),
+			zap.String("tool", tool.Name),
+			zap.Strings("missing", tc.Missing),
+		)
+	}
+	s.AddTool(tool, h)
+}
+
+// addCapabilitiesTool registers get_capabilities last. Its handler is built after the
+// tool itself is recorded, so the report includes every tool and get_capabilities too.
+func (c *capabilities) addCapabilitiesTool(s *server.MCPServer) {
+	var capabilitiesHandler *handler.CapabilitiesHandler
+	c.addTool(s, mcp.NewTool("get_capabilities",
+		mcp.WithDescription("Explain which tools are available with the configured Slack token, which are limited or unavailable, and which OAuth scopes are missing. Call it when a tool you expect is not listed."),
+		mcp.WithTitleAnnotation("Get Capabilities"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return capabilitiesHandler.GetCapabilitiesHandler(ctx, request)
+	})
+	capabilitiesHandler = handler.NewCapabilitiesHandler(c.tools, c.granted(), c.logger)
+}
+
+// skip records a tool that is not registered because a feature it belongs to is off.
+func (c *capabilities) skip(tool, reason string) {
+	c.tools = append(c.tools, handler.ToolCapability{Tool: tool, Reason: reason})
+}
+
+// granted returns the sorted granted scopes, or nil when they are unknown.
+func (c *capabilities) granted() []string {
+	if !c.scopesKnown {
+		return nil
+	}
+	scopes := make([]string, 0, len(c.scopes))
+	for s := range c.scopes {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// checkPolicy reports tool names in the policy that match no tool, which are almost
+// always typos that would silently leave a tool enabled.
+func (c *capabilities) checkPolicy() error {
+	known := make(map[string]bool, len(c.tools))
+	for _, tc := range c.tools {
+		known[tc.Tool] = true
+	}
+	var unknown []string
+	for _, name := range append(slices.Clone(c.policy.Enabled), c.policy.Disabled...) {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown tools in tool policy: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// logEffectiveTools logs the final tool set, so a deployment can be reviewed from its
+// startup log alone.
+func (c *capabilities) logEffectiveTools() {
+	var registered, excluded []string
+	for _, tc := range c.tools {
+		if tc.Available {
+			registered = append(registered, tc.Tool)
+		} else {
+			excluded = append(excluded, fmt.Sprintf("%s (%s)", tc.Tool, tc.Reason))
+		}
+	}
+	c.logger.Info("Effective tool set",
+		zap.String("context", "console"),
+		zap.Bool("read_only", c.policy.ReadOnly),
+		zap.Strings("registered", registered),
+		zap.Strings("excluded", excluded),
+	)
+}
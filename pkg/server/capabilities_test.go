@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestCapabilities(known bool, isBot bool, scopes ...string) *capabilities {
+	granted := make(map[string]bool)
+	for _, s := range scopes {
+		granted[s] = true
+	}
+	return &capabilities{scopes: granted, scopesKnown: known, isBotToken: isBot, logger: zap.NewNop()}
+}
+
+func TestUnitCapabilitiesEvaluate(t *testing.T) {
+	t.Run("unknown scopes allow everything", func(t *testing.T) {
+		c := newTestCapabilities(false, false)
+		for tool := range toolScopes {
+			assert.True(t, c.evaluate(tool).Available, tool)
+		}
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		c := newTestCapabilities(true, false, "channels:history", "channels:read")
+		tc := c.evaluate("conversations_add_message")
+		assert.False(t, tc.Available)
+		assert.Equal(t, []string{"chat:write"}, tc.Missing)
+		assert.Contains(t, tc.Reason, "chat:write")
+	})
+
+	t.Run("partial scope group", func(t *testing.T) {
+		c := newTestCapabilities(true, false, "channels:history")
+		tc := c.evaluate("conversations_history")
+		assert.True(t, tc.Available)
+		assert.True(t, tc.Limited)
+		assert.ElementsMatch(t, []string{"groups:history", "im:history", "mpim:history"}, tc.Missing)
+	})
+
+	t.Run("optional feature scopes", func(t *testing.T) {
+		c := newTestCapabilities(true, false, "chat:write", "im:write")
+		t.Setenv("SLACK_MCP_ADD_MESSAGE_OPEN_DM", "false")
+		tc := c.evaluate("conversations_add_message")
+		assert.True(t, tc.Available)
+		assert.False(t, tc.Limited)
+
+		t.Setenv("SLACK_MCP_ADD_MESSAGE_OPEN_DM", "true")
+		tc = c.evaluate("conversations_add_message")
+		assert.True(t, tc.Available)
+		assert.True(t, tc.Limited)
+		assert.Equal(t, []string{"mpim:write"}, tc.Missing)
+		assert.Contains(t, tc.Reason, "opening new DMs")
+	})
+
+	t.Run("tools without scopes", func(t *testing.T) {
+		c := newTestCapabilities(true, false)
+		tc := c.evaluate("get_team_context")
+		assert.True(t, tc.Available)
+		assert.False(t, tc.Limited)
+	})
+
+	t.Run("bot tokens cannot search", func(t *testing.T) {
+		c := newTestCapabilities(true, true, "search:read")
+		tc := c.evaluate("conversations_search_messages")
+		assert.False(t, tc.Available)
+		assert.Contains(t, tc.Reason, "bot tokens")
+	})
+}
+
+func TestUnitCapabilitiesAddTool(t *testing.T) {
+	c := newTestCapabilities(true, false, "channels:history")
+	s := server.NewMCPServer("test", "0")
+	noop := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) { return nil, nil }
+
+	c.addTool(s, mcp.NewTool("conversations_add_message", mcp.WithDescription("Send.")), noop)
+	c.addTool(s, mcp.NewTool("conversations_history", mcp.WithDescription("History.")), noop)
+
+	assert.Nil(t, s.GetTool("conversations_add_message"))
+	history := s.GetTool("conversations_history")
+	if assert.NotNil(t, history) {
+		assert.Contains(t, history.Tool.Description, "limited by token scopes")
+	}
+	assert.Len(t, c.tools, 2)
+	assert.Equal(t, []string{"channels:history"}, c.granted())
+}
+
+func TestUnitCapabilitiesListsItself(t *testing.T) {
+	c := newTestCapabilities(false, false)
+	s := server.NewMCPServer("test", "0")
+	noop := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) { return nil, nil }
+
+	c.addTool(s, mcp.NewTool("conversations_history", mcp.WithDescription("History.")), noop)
+	c.addCapabilitiesTool(s)
+
+	tool := s.GetTool("get_capabilities")
+	require.NotNil(t, tool)
+	result, err := tool.Handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "- conversations_history\n")
+	assert.Contains(t, text, "- get_capabilities\n")
+}
+
+func TestUnitCapabilitiesPolicy(t *testing.T) {
+	noop := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) { return nil, nil }
+	register := func(c *capabilities) *server.MCPServer {
+		s := server.NewMCPServer("test", "0")
+		c.addTool(s, mcp.NewTool("conversations_history", mcp.WithReadOnlyHintAnnotation(true)), noop)
+		c.addTool(s, mcp.NewTool("channels_list", mcp.WithReadOnlyHintAnnotation(true)), noop)
+		c.addTool(s, mcp.NewTool("conversations_add_message", mcp.WithDestructiveHintAnnotation(true)), noop)
+		return s
+	}
+
+	t.Run("read-only mode", func(t *testing.T) {
+		c := newTestCapabilities(false, false)
+		c.policy = config.Tools{ReadOnly: true}
+		s := register(c)
+		assert.NotNil(t, s.GetTool("conversations_history"))
+		assert.Nil(t, s.GetTool("conversations_add_message"))
+		assert.Contains(t, c.tools[2].Reason, "read-only mode")
+	})
+
+	t.Run("enabled list", func(t *testing.T) {
+		c := newTestCapabilities(false, false)
+		c.policy = config.Tools{Enabled: []string{"channels_list"}}
+		s := register(c)
+		assert.Len(t, s.ListTools(), 1)
+		assert.NotNil(t, s.GetTool("channels_list"))
+		assert.NoError(t, c.checkPolicy())
+	})
+
+	t.Run("disabled list", func(t *testing.T) {
+		c := newTestCapabilities(false, false)
+		c.policy = config.Tools{Disabled: []string{"conversations_history"}}
+		s := register(c)
+		assert.Len(t, s.ListTools(), 2)
+		assert.Nil(t, s.GetTool("conversations_history"))
+	})
+
+	t.Run("unknown tool names", func(t *testing.T) {
+		c := newTestCapabilities(false, false)
+		c.policy = config.Tools{Disabled: []string{"conversations_histroy"}}
+		register(c)
+		err := c.checkPolicy()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "conversations_histroy")
+	})
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	// authCheckTTL bounds how often /readyz calls auth.test, so frequent probes do not
+	// eat into the Slack rate limit.
+	authCheckTTL     = 30 * time.Second
+	authCheckTimeout = 5 * time.Second
+)
+
+type probeStatus struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// readiness reports whether the server can serve tool calls: caches are loaded and the
+// Slack credentials are still valid.
+type readiness struct {
+	provider *provider.ApiProvider
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	authErr   error
+}
+
+func newReadiness(p *provider.ApiProvider, logger *zap.Logger) *readiness {
+	return &readiness{provider: p, logger: logger}
+}
+
+func (r *readiness) checkAuth(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.checkedAt.IsZero() && time.Since(r.checkedAt) < authCheckTTL {
+		return r.authErr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, authCheckTimeout)
+	defer cancel()
+
+	r.authErr = r.provider.CheckAuth(ctx)
+	r.checkedAt = time.Now()
+	if r.authErr != nil {
+		r.logger.Warn("Readiness auth check failed", zap.Error(r.authErr))
+	}
+	return r.authErr
+}
+
+func (r *readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if ready, err := r.provider.IsReady(); !ready {
+		writeProbe(w, http.StatusServiceUnavailable, probeStatus{Status: "warming_up", Reason: err.Error()})
+		return
+	}
+	if err := r.checkAuth(req.Context()); err != nil {
+		writeProbe(w, http.StatusServiceUnavailable, probeStatus{Status: "unauthorized", Reason: err.Error()})
+		return
+	}
+	writeProbe(w, http.StatusOK, probeStatus{Status: "ok"})
+}
+
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	writeProbe(w, http.StatusOK, probeStatus{Status: "ok"})
+}
+
+func writeProbe(w http.ResponseWriter, code int, status probeStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// mountHealth registers the liveness and readiness probes. They are not authenticated
+// so that orchestrators can reach them.
+func (s *MCPServer) mountHealth(mux *http.ServeMux) {
+	mux.HandleFunc(healthzPath, healthz)
+	mux.Handle(readyzPath, s.readiness)
+}
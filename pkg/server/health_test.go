@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newDemoProvider builds a provider with demo credentials, which never talks to Slack,
+// backed by cache files in a temporary directory.
+func newDemoProvider(t *testing.T) (*provider.ApiProvider, string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	usersCache := filepath.Join(dir, "users.json")
+	channelsCache := filepath.Join(dir, "channels.json")
+	t.Setenv("SLACK_MCP_XOXP_TOKEN", "demo")
+	t.Setenv("SLACK_MCP_USERS_CACHE", usersCache)
+	t.Setenv("SLACK_MCP_CHANNELS_CACHE", channelsCache)
+
+	return provider.New("http", zap.NewNop()), usersCache, channelsCache
+}
+
+func TestUnitHealthProbes(t *testing.T) {
+	p, usersCache, channelsCache := newDemoProvider(t)
+	s := &MCPServer{readiness: newReadiness(p, zap.NewNop()), logger: zap.NewNop()}
+	mux := http.NewServeMux()
+	s.mountHealth(mux)
+
+	probe := func(path string) (int, probeStatus) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var status probeStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		return rec.Code, status
+	}
+
+	code, status := probe(healthzPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", status.Status)
+
+	code, status = probe(readyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "warming_up", status.Status)
+	assert.Contains(t, status.Reason, "users")
+
+	require.NoError(t, os.WriteFile(usersCache, []byte(`[{"id":"U1","name":"alice"}]`), 0o600))
+	require.NoError(t, os.WriteFile(channelsCache, []byte(`[{"id":"C1","name":"#general"}]`), 0o600))
+	require.NoError(t, p.RefreshUsers(context.Background()))
+	require.NoError(t, p.RefreshChannels(context.Background()))
+
+	code, status = probe(readyzPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", status.Status)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+)
+
+// Listener owns the *http.Server behind the SSE and HTTP transports so that both can be
+// served over plaintext or TLS the same way.
+type Listener struct {
+	httpServer *http.Server
+	tlsConfig  *tls.Config
+	shutdown   func(ctx context.Context) error
+}
+
+// Scheme returns "https" when the listener terminates TLS and "http" otherwise.
+func (l *Listener) Scheme() string {
+	if l.tlsConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Start listens on addr and blocks until the server stops.
+func (l *Listener) Start(addr string) error {
+	l.httpServer.Addr = addr
+	if l.tlsConfig != nil {
+		l.httpServer.TLSConfig = l.tlsConfig
+		// Certificates are served by tlsConfig, so no files are passed here.
+		return l.httpServer.ListenAndServeTLS("", "")
+	}
+	return l.httpServer.ListenAndServe()
+}
+
+// Shutdown stops the transport and its underlying HTTP server.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	return l.shutdown(ctx)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// metricsPath is where the Prometheus endpoint is mounted.
+const metricsPath = "/metrics"
+
+func buildMetricsMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			res, err := next(ctx, req)
+			metrics.ObserveToolCall(req.Params.Name, err != nil || (res != nil && res.IsError), time.Since(start))
+			return res, err
+		}
+	}
+}
+
+// providerCollector reports cache sizes, cache ages and readiness at scrape time.
+type providerCollector struct {
+	provider *provider.ApiProvider
+
+	ready     *prometheus.Desc
+	cacheSize *prometheus.Desc
+	cacheAge  *prometheus.Desc
+}
+
+func newProviderCollector(p *provider.ApiProvider) *providerCollector {
+	return &providerCollector{
+		provider:  p,
+		ready:     prometheus.NewDesc("slack_mcp_ready", "Whether the users and channels caches are loaded (1) or still warming up (0).", nil, nil),
+		cacheSize: prometheus.NewDesc("slack_mcp_cache_entries", "Number of entries in the users and channels caches.", []string{"cache"}, nil),
+		cacheAge:  prometheus.NewDesc("slack_mcp_cache_age_seconds", "Seconds since the cache was last fetched from Slack.", []string{"cache"}, nil),
+	}
+}
+
+func (c *providerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ready
+	ch <- c.cacheSize
+	ch <- c.cacheAge
+}
+
+func (c *providerCollector) Collect(ch chan<- prometheus.Metric) {
+	ready := 0.0
+	if ok, _ := c.provider.IsReady(); ok {
+		ready = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.ready, prometheus.GaugeValue, ready)
+
+	stats := c.provider.CacheStats()
+	ch <- prometheus.MustNewConstMetric(c.cacheSize, prometheus.GaugeValue, float64(stats.Users), "users")
+	ch <- prometheus.MustNewConstMetric(c.cacheSize, prometheus.GaugeValue, float64(stats.Channels), "channels")
+	if !stats.UsersUpdatedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.cacheAge, prometheus.GaugeValue, time.Since(stats.UsersUpdatedAt).Seconds(), "users")
+	}
+	if !stats.ChannelsUpdatedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.cacheAge, prometheus.GaugeValue, time.Since(stats.ChannelsUpdatedAt).Seconds(), "channels")
+	}
+}
+
+// mountMetrics serves /metrics on the transport listener unless a dedicated metrics
+// port is configured.
+func mountMetrics(mux *http.ServeMux) {
+	if config.Current().Server.MetricsPort != 0 {
+		return
+	}
+	mux.Handle(metricsPath, metrics.Handler())
+}
+
+// ServeMetrics returns a listener serving /metrics, the health probes and the approval
+// review page, used when SLACK_MCP_METRICS_PORT is set and in stdio mode where there is
+// no HTTP listener.
+func (s *MCPServer) ServeMetrics() *Listener {
+	s.logger.Info("Creating metrics server",
+		zap.String("context", "console"),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, metrics.Handler())
+	s.mountHealth(mux)
+	s.mountApprovals(mux)
+	httpServer := &http.Server{Handler: s.rejectWhileDraining(mux)}
+
+	return &Listener{httpServer: httpServer, shutdown: httpServer.Shutdown}
+}
@@ -2,14 +2,22 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
 	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/korotovsky/slack-mcp-server/pkg/version"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,24 +25,44 @@ import (
 )
 
 type MCPServer struct {
-	server *server.MCPServer
-	logger *zap.Logger
+	server     *server.MCPServer
+	readiness  *readiness
+	drainer    *drainer
+	auditLog   *audit.Log
+	approvals  *approval.Queue
+	dispatcher *approval.Dispatcher
+	logger     *zap.Logger
 }
 
 func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer {
+	auditLog := openAuditLog(logger)
+	approvals := openApprovalQueue(logger)
+	drainer := &drainer{}
+
 	s := server.NewMCPServer(
 		"Slack MCP Server",
 		version.Version,
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithElicitation(),
+		server.WithToolHandlerMiddleware(buildDrainMiddleware(drainer)),
+		server.WithToolHandlerMiddleware(buildTracingMiddleware(provider.ServerTransport())),
 		server.WithToolHandlerMiddleware(buildLoggerMiddleware(logger)),
+		server.WithToolHandlerMiddleware(buildMetricsMiddleware()),
+		server.WithToolHandlerMiddleware(buildAuditMiddleware(auditLog, provider.ServerTransport(), logger)),
 		server.WithToolHandlerMiddleware(auth.BuildMiddleware(provider.ServerTransport(), logger)),
 	)
 
-	conversationsHandler := handler.NewConversationsHandler(provider, logger)
+	if err := metrics.Register(newProviderCollector(provider)); err != nil {
+		logger.Warn("Failed to register cache metrics", zap.Error(err))
+	}
+
+	caps := newCapabilities(provider, logger)
 
-	s.AddTool(mcp.NewTool("conversations_history",
-		mcp.WithDescription("Get messages from the channel (or DM) by channel_id. Images are included automatically but may be limited by size. If the response indicates images were skipped, you MUST call get_image for each listed file ID to retrieve them - they often contain critical context."),
+	conversationsHandler := handler.NewConversationsHandler(provider, approvals, logger)
+
+	caps.addTool(s, mcp.NewTool("conversations_history",
+		mcp.WithDescription("Get messages from the channel (or DM) by channel_id. Images are included automatically but may be limited by size. If the response indicates images were skipped, you MUST call get_image for each listed file ID to retrieve them - they often contain critical context. Other attached files are listed in the fileRefs column; read them with get_file."),
 		mcp.WithTitleAnnotation("Get Conversation History"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("channel_id",
@@ -80,8 +108,8 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 		),
 	), conversationsHandler.ConversationsHistoryHandler)
 
-	s.AddTool(mcp.NewTool("conversations_replies",
-		mcp.WithDescription("Get a thread of messages by channel_id and thread_ts. Images are included automatically but may be limited by size. If the response indicates images were skipped, you MUST call get_image for each listed file ID."),
+	caps.addTool(s, mcp.NewTool("conversations_replies",
+		mcp.WithDescription("Get a thread of messages by channel_id and thread_ts. Images are included automatically but may be limited by size. If the response indicates images were skipped, you MUST call get_image for each listed file ID. Other attached files are listed in the fileRefs column; read them with get_file."),
 		mcp.WithTitleAnnotation("Get Thread Replies"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("channel_id",
@@ -113,26 +141,61 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 		),
 	), conversationsHandler.ConversationsRepliesHandler)
 
-	s.AddTool(mcp.NewTool("conversations_add_message",
+	caps.addTool(s, mcp.NewTool("conversations_add_message",
 		mcp.WithDescription("Add a message to a public channel, private channel, or direct message (DM, or IM) conversation by channel_id and thread_ts."),
 		mcp.WithTitleAnnotation("Send Message"),
 		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithString("channel_id",
 			mcp.Required(),
-			mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm. To message users directly, pass a user ID (Uxxxxxxxxxx) or @username, or a comma-separated list of up to 8 users for a group DM; the DM is opened if it does not exist yet."),
 		),
 		mcp.WithString("thread_ts",
 			mcp.Description("Unique identifier of either a thread's parent message or a message in the thread_ts must be the timestamp in format 1234567890.123456 of an existing message with 0 or more replies. Optional, if not provided the message will be added to the channel itself, otherwise it will be added to the thread."),
 		),
 		mcp.WithString("payload",
-			mcp.Description("Message payload in specified content_type format. Example: 'Hello, world!' for text/plain or '# Hello, world!' for text/markdown."),
+			mcp.Description("Message payload in specified content_type format. Example: 'Hello, world!' for text/plain, '# Hello, world!' for text/markdown, or '[{\"type\": \"section\", \"text\": {\"type\": \"mrkdwn\", \"text\": \"*Hello*\"}}]' for application/vnd.slack.blocks+json. In text/markdown and text/plain, @username, @Real Name, @usergroup-handle and #channel-name are converted to Slack mentions and channel links."),
 		),
 		mcp.WithString("content_type",
 			mcp.DefaultString("text/markdown"),
-			mcp.Description("Content type of the message. Default is 'text/markdown'. Allowed values: 'text/markdown', 'text/plain'."),
+			mcp.Description("Content type of the message. Default is 'text/markdown'. Allowed values: 'text/markdown', 'text/plain', 'application/vnd.slack.blocks+json' (a JSON array of Block Kit blocks, or {\"blocks\": [...], \"text\": \"notification fallback\"}) and 'application/vnd.slack.attachments+json' (a JSON array of legacy attachments). Block Kit payloads are validated before posting."),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.DefaultBool(false),
+			mcp.Description("If true, nothing is posted. Returns the exact chat.postMessage request (Block Kit JSON, unfurl settings) and a plain-text rendering, to check formatting before posting."),
 		),
 	), conversationsHandler.ConversationsAddMessageHandler)
 
+	caps.addTool(s, mcp.NewTool("files_upload",
+		mcp.WithDescription("Upload a file, such as a chart, log or CSV report, to a public channel, private channel, or direct message (DM, or IM) conversation, optionally in a thread and with a comment. Returns the Slack file ID; uploaded images can be viewed with get_image."),
+		mcp.WithTitleAnnotation("Upload File"),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("channel_id",
+			mcp.Required(),
+			mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm. To share with users directly, pass a user ID (Uxxxxxxxxxx) or @username, or a comma-separated list of up to 8 users for a group DM."),
+		),
+		mcp.WithString("content_base64",
+			mcp.Description("File content encoded as standard base64. Exactly one of content_base64 and path is required."),
+		),
+		mcp.WithString("path",
+			mcp.Description("Path of a file on the server, absolute or relative to the configured upload directory. Only files inside that directory can be uploaded."),
+		),
+		mcp.WithString("filename",
+			mcp.Description("Name of the file in Slack, e.g. 'report.csv'. Required with content_base64; defaults to the base name of path."),
+		),
+		mcp.WithString("title",
+			mcp.Description("Title of the file. Defaults to the filename."),
+		),
+		mcp.WithString("initial_comment",
+			mcp.Description("Message text posted together with the file."),
+		),
+		mcp.WithString("thread_ts",
+			mcp.Description("Timestamp of the parent message in format 1234567890.123456 to upload the file into its thread."),
+		),
+		mcp.WithString("alt_text",
+			mcp.Description("Description of an image for screen readers."),
+		),
+	), conversationsHandler.FilesUploadHandler)
+
 	conversationsSearchTool := mcp.NewTool("conversations_search_messages",
 		mcp.WithDescription("Search messages in a public channel, private channel, or direct message (DM, or IM) conversation using filters. All filters are optional, if not provided then search_query is required. IMPORTANT: Workspace conversations may be in Spanish as well as English. When searching for concepts or keywords, try multiple queries using both English terms AND equivalent Spanish terms. For example, if searching for 'funnel status' also try 'estado del embudo' or 'estado funnel'. This significantly improves recall for multilingual workspaces."),
 		mcp.WithTitleAnnotation("Search Messages"),
@@ -192,14 +255,12 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.DefaultNumber(25),
 		),
 	)
-	// Only register search tool for non-bot tokens (bot tokens cannot use search.messages API)
-	if !provider.IsBotToken() {
-		s.AddTool(conversationsSearchTool, conversationsHandler.ConversationsSearchHandler)
-	}
+	// Bot tokens cannot use the search.messages API, see capabilities.evaluate
+	caps.addTool(s, conversationsSearchTool, conversationsHandler.ConversationsSearchHandler)
 
 	channelsHandler := handler.NewChannelsHandler(provider, logger)
 
-	s.AddTool(mcp.NewTool("channels_list",
+	caps.addTool(s, mcp.NewTool("channels_list",
 		mcp.WithDescription("Get list of channels"),
 		mcp.WithTitleAnnotation("List Channels"),
 		mcp.WithReadOnlyHintAnnotation(true),
@@ -263,7 +324,7 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 
 	// Add team context tool for priority channels and users
 	teamContextHandler := handler.NewTeamContextHandler(provider, logger)
-	s.AddTool(mcp.NewTool("get_team_context",
+	caps.addTool(s, mcp.NewTool("get_team_context",
 		mcp.WithDescription("Retrieves the configured priority channels and key team members for this Slack workspace. Call this tool FIRST when asked to summarize Slack activity, check messages, or search conversations - it tells you which channels and users are most important to focus on. Returns channel names/IDs, user names/IDs, and usage guidelines."),
 		mcp.WithTitleAnnotation("Get Team Context"),
 		mcp.WithReadOnlyHintAnnotation(true),
@@ -271,7 +332,7 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 
 	// Add get_image tool for fetching images by file ID
 	imagesHandler := handler.NewImagesHandler(provider, logger)
-	s.AddTool(mcp.NewTool("get_image",
+	caps.addTool(s, mcp.NewTool("get_image",
 		mcp.WithDescription("Fetch a single image by its Slack file ID. Use this to retrieve images that were not included inline due to size limits. The file ID can be found in the imageRefs column of conversation history."),
 		mcp.WithTitleAnnotation("Get Image"),
 		mcp.WithReadOnlyHintAnnotation(true),
@@ -279,15 +340,73 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.Required(),
 			mcp.Description("The Slack file ID (e.g., F0AAJ80JHL7) from the imageRefs column in conversation history."),
 		),
+		mcp.WithString("size",
+			mcp.DefaultString("full"),
+			mcp.Enum("thumb", "medium", "full"),
+			mcp.Description("Image variant: 'thumb' for Slack's smallest thumbnail (360px), 'medium' for its largest (up to 1024px), or 'full' for the original. Originals over the size limit fall back to 'medium'."),
+		),
 	), imagesHandler.GetImageHandler)
 
+	filesHandler := handler.NewFilesHandler(provider, logger)
+	caps.addTool(s, mcp.NewTool("get_file",
+		mcp.WithDescription("Fetch a non-image file by its Slack file ID and return its text: the content of text files and snippets, or the text extracted from PDFs and Word, Excel and PowerPoint documents. Other binary files return metadata only. The file ID can be found in the fileRefs column of conversation history; use get_image for images."),
+		mcp.WithTitleAnnotation("Get File"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file_id",
+			mcp.Required(),
+			mcp.Description("The Slack file ID (e.g., F0AAJ80JHL7) from the fileRefs column in conversation history."),
+		),
+	), filesHandler.GetFileHandler)
+
+	statusHandler := handler.NewStatusHandler(provider, logger)
+	caps.addTool(s, mcp.NewTool("server_status",
+		mcp.WithDescription("Report the server version, Slack credential type, registered tools, cache sizes and ages, and rate limiter state. Use it to diagnose why other tools fail or return stale data."),
+		mcp.WithTitleAnnotation("Server Status"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), statusHandler.ServerStatusHandler)
+
+	var dispatcher *approval.Dispatcher
+	if approvals != nil {
+		dispatcher = newApprovalDispatcher(approvals, conversationsHandler, auditLog, provider.ServerTransport(), logger)
+
+		pendingActionsHandler := handler.NewPendingActionsHandler(approvals, logger)
+		caps.addTool(s, mcp.NewTool("pending_actions_status",
+			mcp.WithDescription("Check the review status of write actions queued for human approval. Pass the id returned when the action was queued, or omit it to list the most recent actions. Statuses: pending, approved, rejected (see reason), posted (see ts and permalink) or failed (see error). Reviewers may have edited the text before approving."),
+			mcp.WithTitleAnnotation("Pending Actions Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("id",
+				mcp.Description("ID of a queued action, e.g. pa_0123456789abcdef. If omitted, the most recent actions are listed."),
+			),
+			mcp.WithString("status",
+				mcp.Description("When listing, only return actions with this status: pending, approved, rejected, posted or failed."),
+			),
+		), pendingActionsHandler.PendingActionsStatusHandler)
+	} else {
+		caps.skip("pending_actions_status", "approval mode is disabled")
+	}
+
+	caps.addCapabilitiesTool(s)
+
+	if err := caps.checkPolicy(); err != nil {
+		logger.Fatal("Invalid tool policy",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+	caps.logEffectiveTools()
+
 	return &MCPServer{
-		server: s,
-		logger: logger,
+		server:     s,
+		readiness:  newReadiness(provider, logger),
+		drainer:    drainer,
+		auditLog:   auditLog,
+		approvals:  approvals,
+		dispatcher: dispatcher,
+		logger:     logger,
 	}
 }
 
-func (s *MCPServer) ServeSSE(addr string) *server.SSEServer {
+func (s *MCPServer) ServeSSE(addr string) *Listener {
 	s.logger.Info("Creating SSE server",
 		zap.String("context", "console"),
 		zap.String("version", version.Version),
@@ -295,17 +414,33 @@ func (s *MCPServer) ServeSSE(addr string) *server.SSEServer {
 		zap.String("commit_hash", version.CommitHash),
 		zap.String("address", addr),
 	)
-	return server.NewSSEServer(s.server,
-		server.WithBaseURL(fmt.Sprintf("http://%s", addr)),
+
+	tlsConfig := s.loadTLSConfig()
+
+	mux := http.NewServeMux()
+	httpServer := &http.Server{Handler: s.rejectWhileDraining(mux)}
+	listener := &Listener{httpServer: httpServer, tlsConfig: tlsConfig}
+
+	sseServer := server.NewSSEServer(s.server,
+		server.WithBaseURL(fmt.Sprintf("%s://%s", listener.Scheme(), addr)),
+		server.WithHTTPServer(httpServer),
 		server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
 			ctx = auth.AuthFromRequest(s.logger)(ctx, r)
+			ctx = tracing.FromRequest(ctx, r)
 
 			return ctx
 		}),
 	)
+	mux.Handle("/", sseServer)
+	mountMetrics(mux)
+	s.mountHealth(mux)
+	s.mountApprovals(mux)
+	listener.shutdown = sseServer.Shutdown
+
+	return listener
 }
 
-func (s *MCPServer) ServeHTTP(addr string) *server.StreamableHTTPServer {
+func (s *MCPServer) ServeHTTP(addr string) *Listener {
 	s.logger.Info("Creating HTTP server",
 		zap.String("context", "console"),
 		zap.String("version", version.Version),
@@ -313,36 +448,110 @@ func (s *MCPServer) ServeHTTP(addr string) *server.StreamableHTTPServer {
 		zap.String("commit_hash", version.CommitHash),
 		zap.String("address", addr),
 	)
-	return server.NewStreamableHTTPServer(s.server,
+
+	oauthConfig, err := auth.LoadOAuthConfig()
+	if err != nil {
+		s.logger.Fatal("Invalid OAuth configuration",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+
+	tlsConfig := s.loadTLSConfig()
+
+	mux := http.NewServeMux()
+	listener := &Listener{httpServer: &http.Server{Handler: s.rejectWhileDraining(mux)}, tlsConfig: tlsConfig}
+	httpServer := server.NewStreamableHTTPServer(s.server,
 		server.WithEndpointPath("/mcp"),
+		server.WithStreamableHTTPServer(listener.httpServer),
 		server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
 			ctx = auth.AuthFromRequest(s.logger)(ctx, r)
+			ctx = tracing.FromRequest(ctx, r)
 
 			return ctx
 		}),
 	)
+
+	var mcpHandler http.Handler = httpServer
+	if oauthConfig != nil {
+		s.logger.Info("OAuth authorization enabled for HTTP transport",
+			zap.String("context", "console"),
+			zap.String("issuer", oauthConfig.Issuer),
+			zap.String("resource", oauthConfig.Resource),
+			zap.Bool("introspection", oauthConfig.IntrospectionURL != ""),
+		)
+
+		metadata := auth.ProtectedResourceMetadataHandler(oauthConfig)
+		mux.Handle(auth.ProtectedResourceMetadataPath, metadata)
+		mux.Handle(auth.ProtectedResourceMetadataPath+"/", metadata)
+
+		mcpHandler = auth.OAuthHandler(oauthConfig, auth.NewTokenValidator(oauthConfig, nil), s.logger, httpServer)
+	}
+	mux.Handle("/mcp", mcpHandler)
+	mountMetrics(mux)
+	s.mountHealth(mux)
+	s.mountApprovals(mux)
+	listener.shutdown = httpServer.Shutdown
+
+	return listener
 }
 
-func (s *MCPServer) ServeStdio() error {
+// loadTLSConfig builds the listener TLS config from the environment, or returns nil
+// when TLS is not configured.
+func (s *MCPServer) loadTLSConfig() *tls.Config {
+	settings, err := LoadTLSSettings()
+	if err != nil {
+		s.logger.Fatal("Invalid TLS configuration",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+	if settings == nil {
+		return nil
+	}
+
+	reloader, err := newCertReloader(*settings, s.logger)
+	if err != nil {
+		s.logger.Fatal("Failed to load TLS certificates",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Info("TLS enabled",
+		zap.String("context", "console"),
+		zap.String("cert", settings.CertFile),
+		zap.Bool("mtls", settings.ClientCAFile != ""),
+	)
+
+	return reloader.TLSConfig()
+}
+
+// ServeStdio serves MCP over stdin/stdout until ctx is cancelled or stdin is closed.
+func (s *MCPServer) ServeStdio(ctx context.Context) error {
 	s.logger.Info("Starting STDIO server",
 		zap.String("version", version.Version),
 		zap.String("build_time", version.BuildTime),
 		zap.String("commit_hash", version.CommitHash),
 	)
-	err := server.ServeStdio(s.server)
-	if err != nil {
+	err := server.NewStdioServer(s.server).Listen(ctx, os.Stdin, os.Stdout)
+	if err != nil && !errors.Is(err, context.Canceled) {
 		s.logger.Error("STDIO server error", zap.Error(err))
+		return err
 	}
-	return err
+	return nil
 }
 
 func buildLoggerMiddleware(logger *zap.Logger) server.ToolHandlerMiddleware {
 	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Arguments carry message text and files, so only their names are logged
+			// at info level; mutating calls are covered by the audit log.
 			logger.Info("Request received",
 				zap.String("tool", req.Params.Name),
-				zap.Any("params", req.Params),
+				zap.Strings("params", argumentNames(req)),
 			)
+			logger.Debug("Request params", zap.String("tool", req.Params.Name), zap.Any("params", req.Params))
 
 			startTime := time.Now()
 
@@ -359,3 +568,14 @@ func buildLoggerMiddleware(logger *zap.Logger) server.ToolHandlerMiddleware {
 		}
 	}
 }
+
+// argumentNames returns the sorted names of the arguments of a tool call.
+func argumentNames(req mcp.CallToolRequest) []string {
+	args := req.GetArguments()
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// errShuttingDown is returned to tool calls that arrive after shutdown started.
+var errShuttingDown = errors.New("server is shutting down, retry against another instance")
+
+// drainer tracks in-flight tool calls so shutdown can wait for them to finish.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// enter registers a tool call; it returns false once draining has started.
+func (d *drainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight.Add(1)
+	return true
+}
+
+func (d *drainer) leave() {
+	d.inflight.Done()
+}
+
+func (d *drainer) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// drain stops accepting tool calls and waits for in-flight ones or for ctx to expire.
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildDrainMiddleware(d *drainer) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !d.enter() {
+				return nil, errShuttingDown
+			}
+			defer d.leave()
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// rejectWhileDraining answers new HTTP requests with 503 once shutdown has started, so
+// load balancers move clients elsewhere while in-flight tool calls finish. Liveness
+// stays green until the process exits.
+func (s *MCPServer) rejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.drainer.isDraining() && r.URL.Path != healthzPath {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, errShuttingDown.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Shutdown stops accepting tool calls, waits for in-flight ones until ctx expires and
+// then closes persistent stores. When calls are still running at the deadline the audit
+// log stays open, so a call that completes late can still record what it did; every
+// entry is synced as it is written. Transport listeners are shut down separately by the
+// caller, after Shutdown returns.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	s.logger.Info("Draining in-flight tool calls",
+		zap.String("context", "console"),
+	)
+	err := s.drainer.drain(ctx)
+	if err != nil {
+		s.logger.Warn("Shutdown deadline reached with tool calls still running, leaving the audit log open",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if s.auditLog != nil {
+		if cErr := s.auditLog.Close(); cErr != nil {
+			s.logger.Error("Failed to close audit log", zap.Error(cErr))
+		}
+	}
+	return nil
+}
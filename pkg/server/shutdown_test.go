@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitDrainMiddleware(t *testing.T) {
+	d := &drainer{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := buildDrainMiddleware(d)(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("posted"), nil
+	})
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "conversations_add_message"
+
+	result := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := handler(context.Background(), req)
+		result <- res
+	}()
+	<-started
+
+	// The in-flight call holds up draining until the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.drain(ctx), context.DeadlineExceeded)
+
+	// New calls are rejected once draining started.
+	_, err := handler(context.Background(), req)
+	assert.ErrorIs(t, err, errShuttingDown)
+
+	close(release)
+	require.NoError(t, d.drain(context.Background()))
+	assert.NotNil(t, <-result)
+}
+
+func TestUnitRejectWhileDraining(t *testing.T) {
+	s := &MCPServer{drainer: &drainer{}, logger: zap.NewNop()}
+	h := s.rejectWhileDraining(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/mcp"))
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/mcp"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(readyzPath))
+	assert.Equal(t, http.StatusOK, serve(healthzPath))
+}
+
+func TestUnitShutdownKeepsAuditLogForLateCalls(t *testing.T) {
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	s := &MCPServer{drainer: &drainer{}, auditLog: auditLog, logger: zap.NewNop()}
+
+	require.True(t, s.drainer.enter())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+
+	// The stuck call completes after the deadline and still records its entry.
+	require.NoError(t, auditLog.Record(audit.Entry{Tool: "conversations_add_message"}))
+	s.drainer.leave()
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.Error(t, auditLog.Record(audit.Entry{Tool: "conversations_add_message"}))
+}
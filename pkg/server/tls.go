@@ -0,0 +1,185 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+// tlsReloadInterval bounds how often certificate files are checked for rotation.
+const tlsReloadInterval = 10 * time.Second
+
+// TLSSettings configures HTTPS and optional client certificate verification for the
+// SSE and HTTP listeners.
+type TLSSettings struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS: clients must present a certificate signed by one
+	// of the CAs in this PEM bundle.
+	ClientCAFile string
+}
+
+// LoadTLSSettings reads TLS settings from the server configuration.
+// It returns nil when neither SLACK_MCP_TLS_CERT nor SLACK_MCP_TLS_KEY is set.
+func LoadTLSSettings() (*TLSSettings, error) {
+	tlsConfig := config.Current().Server.TLS
+	s := &TLSSettings{
+		CertFile:     tlsConfig.Cert,
+		KeyFile:      tlsConfig.Key,
+		ClientCAFile: tlsConfig.ClientCA,
+	}
+
+	if s.CertFile == "" && s.KeyFile == "" {
+		if s.ClientCAFile != "" {
+			return nil, errors.New("SLACK_MCP_TLS_CLIENT_CA requires SLACK_MCP_TLS_CERT and SLACK_MCP_TLS_KEY")
+		}
+		return nil, nil
+	}
+	if s.CertFile == "" || s.KeyFile == "" {
+		return nil, errors.New("both SLACK_MCP_TLS_CERT and SLACK_MCP_TLS_KEY must be set to enable TLS")
+	}
+
+	return s, nil
+}
+
+// certReloader serves the current certificate and client CA pool, re-reading them from
+// disk when their modification time changes so rotated certificates are picked up
+// without restarting the server.
+type certReloader struct {
+	settings TLSSettings
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(settings TLSSettings, logger *zap.Logger) (*certReloader, error) {
+	r := &certReloader{
+		settings: settings,
+		logger:   logger,
+		modTimes: make(map[string]time.Time),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.settings.CertFile, r.settings.KeyFile}
+	if r.settings.ClientCAFile != "" {
+		files = append(files, r.settings.ClientCAFile)
+	}
+	return files
+}
+
+// load reads the key pair and CA bundle. Callers must hold r.mu or own r exclusively.
+func (r *certReloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, f := range r.files() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		modTimes[f] = fi.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.settings.CertFile, r.settings.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.settings.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.settings.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA bundle %s", r.settings.ClientCAFile)
+		}
+	}
+
+	r.cert = &cert
+	r.clientCAs = pool
+	r.modTimes = modTimes
+	return nil
+}
+
+// maybeReload reloads the files if any of them changed since the last load. A failed
+// reload keeps serving the previous certificate, which is safer than refusing handshakes
+// while a rotation is half written.
+func (r *certReloader) maybeReload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < tlsReloadInterval {
+		return
+	}
+	r.checkedAt = time.Now()
+
+	changed := false
+	for _, f := range r.files() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			r.logger.Warn("Failed to stat TLS file", zap.String("file", f), zap.Error(err))
+			return
+		}
+		if !fi.ModTime().Equal(r.modTimes[f]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		r.logger.Error("Failed to reload TLS certificates, keeping previous ones", zap.Error(err))
+		return
+	}
+	r.logger.Info("Reloaded TLS certificates",
+		zap.String("context", "console"),
+		zap.String("cert", r.settings.CertFile),
+		zap.Bool("mtls", r.settings.ClientCAFile != ""),
+	)
+}
+
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.maybeReload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.clientCAs
+}
+
+// TLSConfig returns a server config that resolves the certificate and client CAs per
+// handshake.
+func (r *certReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
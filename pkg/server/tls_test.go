@@ -0,0 +1,201 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pair tls.Certificate
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pair: tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+	}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+func TestUnitCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	settings := TLSSettings{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+
+	ca := newTestCert(t, "test-ca", nil)
+	ca.write(t, settings.ClientCAFile, "")
+	first := newTestCert(t, "server-1", ca)
+	first.write(t, settings.CertFile, settings.KeyFile)
+
+	reloader, err := newCertReloader(settings, zap.NewNop())
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = reloader.TLSConfig()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := newTestCert(t, "client", ca)
+
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+		return c.Get(srv.URL)
+	}
+
+	t.Run("client certificate required", func(t *testing.T) {
+		resp, err := get()
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err)
+	})
+
+	t.Run("client certificate from untrusted CA", func(t *testing.T) {
+		other := newTestCert(t, "other-ca", nil)
+		resp, err := get(newTestCert(t, "intruder", other).pair)
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err)
+	})
+
+	t.Run("trusted client certificate", func(t *testing.T) {
+		resp, err := get(client.pair)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "server-1", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	t.Run("rotated certificate is picked up", func(t *testing.T) {
+		second := newTestCert(t, "server-2", ca)
+		second.write(t, settings.CertFile, settings.KeyFile)
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(settings.CertFile, future, future))
+
+		reloader.mu.Lock()
+		reloader.checkedAt = time.Time{}
+		reloader.mu.Unlock()
+
+		resp, err := get(client.pair)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "server-2", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+}
+
+func TestUnitLoadTLSSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    *TLSSettings
+		wantErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name: "cert and key",
+			env:  map[string]string{"SLACK_MCP_TLS_CERT": "a.crt", "SLACK_MCP_TLS_KEY": "a.key"},
+			want: &TLSSettings{CertFile: "a.crt", KeyFile: "a.key"},
+		},
+		{
+			name:    "cert without key",
+			env:     map[string]string{"SLACK_MCP_TLS_CERT": "a.crt"},
+			wantErr: true,
+		},
+		{
+			name:    "client CA without cert",
+			env:     map[string]string{"SLACK_MCP_TLS_CLIENT_CA": "ca.crt"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"SLACK_MCP_TLS_CERT", "SLACK_MCP_TLS_KEY", "SLACK_MCP_TLS_CLIENT_CA"} {
+				t.Setenv(k, tt.env[k])
+			}
+
+			got, err := LoadTLSSettings()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// buildTracingMiddleware wraps every tool call in a span so the Slack requests and
+// image processing it triggers are grouped under it.
+func buildTracingMiddleware(transport string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, span := tracing.Start(ctx, "tool "+req.Params.Name,
+				attribute.String("mcp.tool.name", req.Params.Name),
+				attribute.String("mcp.transport", transport),
+			)
+
+			res, err := next(ctx, req)
+
+			spanErr := err
+			if spanErr == nil && res != nil && res.IsError {
+				spanErr = errors.New(toolResultText(res))
+			}
+			tracing.End(span, spanErr)
+
+			return res, err
+		}
+	}
+}
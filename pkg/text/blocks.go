@@ -0,0 +1,120 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// BlocksToText renders Block Kit blocks as plain text, roughly the way Slack displays
+// them: one paragraph per block, lists with bullets or numbers, quotes prefixed with
+// "> " and preformatted text fenced with ```.
+func BlocksToText(blocks []slack.Block) string {
+	var paragraphs []string
+	for _, block := range blocks {
+		var p string
+		switch b := block.(type) {
+		case *slack.HeaderBlock:
+			p = textObject(b.Text)
+		case *slack.SectionBlock:
+			parts := []string{textObject(b.Text)}
+			for _, field := range b.Fields {
+				parts = append(parts, textObject(field))
+			}
+			p = strings.TrimSpace(strings.Join(parts, "\n"))
+		case *slack.ContextBlock:
+			var parts []string
+			for _, el := range b.ContextElements.Elements {
+				if obj, ok := el.(*slack.TextBlockObject); ok {
+					parts = append(parts, obj.Text)
+				}
+			}
+			p = strings.Join(parts, " ")
+		case *slack.MarkdownBlock:
+			p = b.Text
+		case *slack.DividerBlock:
+			p = "---"
+		case *slack.ImageBlock:
+			p = fmt.Sprintf("[image: %s]", b.AltText)
+		case *slack.RichTextBlock:
+			var parts []string
+			for _, el := range b.Elements {
+				parts = append(parts, richTextElement(el))
+			}
+			p = strings.Join(parts, "\n")
+		default:
+			p = fmt.Sprintf("[%s block]", block.BlockType())
+		}
+		if p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func textObject(obj *slack.TextBlockObject) string {
+	if obj == nil {
+		return ""
+	}
+	return obj.Text
+}
+
+func richTextElement(el slack.RichTextElement) string {
+	switch e := el.(type) {
+	case *slack.RichTextSection:
+		return richTextSection(e.Elements)
+	case slack.RichTextSection:
+		return richTextSection(e.Elements)
+	case *slack.RichTextList:
+		return richTextList(*e)
+	case slack.RichTextList:
+		return richTextList(e)
+	case *slack.RichTextQuote:
+		lines := strings.Split(richTextSection(e.Elements), "\n")
+		return "> " + strings.Join(lines, "\n> ")
+	case *slack.RichTextPreformatted:
+		return "```\n" + strings.TrimSuffix(richTextSection(e.Elements), "\n") + "\n```"
+	}
+	return ""
+}
+
+func richTextList(list slack.RichTextList) string {
+	indent := strings.Repeat("  ", list.Indent)
+	lines := make([]string, 0, len(list.Elements))
+	for i, item := range list.Elements {
+		marker := "•"
+		if list.Style == slack.RTEListOrdered {
+			marker = fmt.Sprintf("%d.", list.Offset+i+1)
+		}
+		lines = append(lines, indent+marker+" "+richTextElement(item))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func richTextSection(elements []slack.RichTextSectionElement) string {
+	var b strings.Builder
+	for _, el := range elements {
+		switch e := el.(type) {
+		case *slack.RichTextSectionTextElement:
+			b.WriteString(e.Text)
+		case *slack.RichTextSectionLinkElement:
+			if e.Text != "" && e.Text != e.URL {
+				fmt.Fprintf(&b, "%s (%s)", e.Text, e.URL)
+			} else {
+				b.WriteString(e.URL)
+			}
+		case *slack.RichTextSectionUserElement:
+			fmt.Fprintf(&b, "<@%s>", e.UserID)
+		case *slack.RichTextSectionChannelElement:
+			fmt.Fprintf(&b, "<#%s>", e.ChannelID)
+		case *slack.RichTextSectionUserGroupElement:
+			fmt.Fprintf(&b, "<!subteam^%s>", e.UsergroupID)
+		case *slack.RichTextSectionBroadcastElement:
+			fmt.Fprintf(&b, "<!%s>", e.Range)
+		case *slack.RichTextSectionEmojiElement:
+			fmt.Fprintf(&b, ":%s:", e.Name)
+		}
+	}
+	return b.String()
+}
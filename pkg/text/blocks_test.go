@@ -0,0 +1,35 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitBlocksToText(t *testing.T) {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Release", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "Shipped *v2*", false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewRichTextBlock("",
+			slack.NewRichTextList(slack.RTEListOrdered, 0,
+				slack.NewRichTextSection(slack.NewRichTextSectionTextElement("api", nil)),
+				slack.NewRichTextSection(
+					slack.NewRichTextSectionTextElement("ask ", nil),
+					slack.NewRichTextSectionUserElement("U1", nil),
+				),
+			),
+			&slack.RichTextQuote{Type: slack.RTEQuote, Elements: []slack.RichTextSectionElement{
+				slack.NewRichTextSectionLinkElement("https://example.com", "docs", nil),
+			}},
+			&slack.RichTextPreformatted{RichTextSection: slack.RichTextSection{Type: slack.RTEPreformatted, Elements: []slack.RichTextSectionElement{
+				slack.NewRichTextSectionTextElement("go test ./...\n", nil),
+			}}},
+		),
+	}
+
+	want := "Release\n\nShipped *v2*\n\n---\n\n1. api\n2. ask <@U1>\n> docs (https://example.com)\n```\ngo test ./...\n```"
+	assert.Equal(t, want, BlocksToText(blocks))
+	assert.Empty(t, BlocksToText(nil))
+}
@@ -0,0 +1,136 @@
+// Package tracing configures OpenTelemetry tracing and provides the spans shared by
+// the tool middleware, the Slack HTTP client and the image pipeline.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/korotovsky/slack-mcp-server/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/korotovsky/slack-mcp-server"
+
+// Enabled reports whether an OTLP endpoint is configured through the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables.
+func Enabled() bool {
+	if v, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); v {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs a global tracer provider exporting over OTLP/HTTP. The exporter reads
+// the remaining OTEL_EXPORTER_OTLP_* variables (headers, TLS, timeouts) itself. When
+// tracing is not enabled Setup is a no-op. The returned function flushes pending spans.
+func Setup(ctx context.Context, logger *zap.Logger) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName()),
+		semconv.ServiceVersion(version.Version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	logger.Info("OpenTelemetry tracing enabled",
+		zap.String("context", "console"),
+		zap.String("service", serviceName()),
+	)
+
+	return tp.Shutdown, nil
+}
+
+func serviceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return version.BinaryName
+}
+
+// Start starts a span using the global tracer provider.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// roundTripper creates a client span for every request to Slack, as a child of the
+// span in the request context.
+type roundTripper struct {
+	next http.RoundTripper
+}
+
+// NewRoundTripper wraps next with client spans.
+func NewRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &roundTripper{next: next}
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := metrics.SlackMethod(req.URL.Host, req.URL.Path)
+	ctx, span := otel.Tracer(instrumentationName).Start(req.Context(), "slack "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("slack.method", method),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Host),
+		),
+	)
+	defer span.End()
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		span.SetAttributes(attribute.String("slack.retry_after", resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// FromRequest continues a trace propagated by the MCP client in the request headers.
+func FromRequest(ctx context.Context, r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newRecorder installs an in-memory tracer provider for the duration of the test.
+func newRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prev)
+	})
+	return exporter
+}
+
+func TestUnitRoundTripperSpans(t *testing.T) {
+	exporter := newRecorder(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat.postMessage" {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewRoundTripper(http.DefaultTransport)}
+
+	ctx, parent := Start(context.Background(), "tool conversations_history")
+	for _, path := range []string{"/api/conversations.history", "/api/chat.postMessage"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	history, post, tool := spans[0], spans[1], spans[2]
+	assert.Equal(t, "slack conversations.history", history.Name)
+	assert.Equal(t, "slack chat.postMessage", post.Name)
+	assert.Equal(t, "tool conversations_history", tool.Name)
+
+	for _, child := range []tracetest.SpanStub{history, post} {
+		assert.Equal(t, tool.SpanContext.TraceID(), child.SpanContext.TraceID())
+		assert.Equal(t, tool.SpanContext.SpanID(), child.Parent.SpanID())
+	}
+	assert.Equal(t, codes.Unset, history.Status.Code)
+	assert.Equal(t, codes.Error, post.Status.Code)
+}
+
+func TestUnitEndRecordsError(t *testing.T) {
+	exporter := newRecorder(t)
+
+	_, span := Start(context.Background(), "DownloadImage")
+	End(span, errors.New("boom"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "boom", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
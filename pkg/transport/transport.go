@@ -12,11 +12,13 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	utls "github.com/refraction-networking/utls"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
@@ -337,13 +339,15 @@ func detectBrowserFromUserAgent(userAgent string) utls.ClientHelloID {
 
 // ProvideHTTPClient creates an HTTP client with optional uTLS support
 func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client {
-	if os.Getenv("SLACK_MCP_PROXY") != "" && os.Getenv("SLACK_MCP_CUSTOM_TLS") != "" {
+	network := config.Current().Network
+
+	if network.Proxy != "" && network.CustomTLS {
 		logger.Fatal("SLACK_MCP_PROXY and SLACK_MCP_CUSTOM_TLS cannot be used together",
 			zap.String("reason", "Custom TLS fingerprinting has no effect when using a proxy, as the target server sees the proxy's TLS handshake"))
 	}
 
 	var proxy func(*http.Request) (*url.URL, error)
-	if proxyURL := os.Getenv("SLACK_MCP_PROXY"); proxyURL != "" {
+	if proxyURL := network.Proxy; proxyURL != "" {
 		parsed, err := url.Parse(proxyURL)
 		if err != nil {
 			logger.Fatal("Failed to parse proxy URL",
@@ -358,13 +362,13 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 		rootCAs = x509.NewCertPool()
 	}
 
-	if isToolkit := os.Getenv("SLACK_MCP_SERVER_CA_TOOLKIT"); isToolkit != "" {
+	if network.ServerCAToolkit {
 		if ok := rootCAs.AppendCertsFromPEM([]byte(toolkitPEM)); !ok {
 			logger.Warn("Failed to append toolkit certificate")
 		}
 	}
 
-	if localCertFile := os.Getenv("SLACK_MCP_SERVER_CA"); localCertFile != "" {
+	if localCertFile := network.ServerCA; localCertFile != "" {
 		certs, err := ioutil.ReadFile(localCertFile)
 		if err != nil {
 			logger.Fatal("Failed to read local certificate file",
@@ -377,21 +381,21 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 	}
 
 	insecure := false
-	if os.Getenv("SLACK_MCP_SERVER_CA_INSECURE") != "" {
-		if localCertFile := os.Getenv("SLACK_MCP_SERVER_CA"); localCertFile != "" {
+	if network.ServerCAInsecure {
+		if network.ServerCA != "" {
 			logger.Fatal("SLACK_MCP_SERVER_CA and SLACK_MCP_SERVER_CA_INSECURE cannot be used together")
 		}
 		insecure = true
 	}
 
 	userAgent := defaultUA
-	if ua := os.Getenv("SLACK_MCP_USER_AGENT"); ua != "" {
+	if ua := network.UserAgent; ua != "" {
 		userAgent = ua
 	}
 
 	var transport http.RoundTripper
 
-	if useCustomTLS := os.Getenv("SLACK_MCP_CUSTOM_TLS"); useCustomTLS != "" {
+	if network.CustomTLS {
 		logger.Debug("Custom TLS handshake enabled",
 			zap.String("user_agent", userAgent))
 
@@ -443,6 +447,8 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 	}
 
 	transport = NewUserAgentTransport(transport, userAgent, cookies, logger)
+	transport = metrics.NewRoundTripper(transport)
+	transport = tracing.NewRoundTripper(transport)
 
 	// Create a cookie jar for proper cookie domain handling
 	// This is CRITICAL for file downloads with browser tokens (xoxc/xoxd)